@@ -17,6 +17,10 @@ type requestError struct {
 	Cause        string `json:"cause"`
 	Causes       []any  `json:"causes"`
 	ErrorStatus  int    `json:"status"`
+	// Errors lists the invalid fields of a validation failure.
+	Errors FieldErrors `json:"errors,omitempty"`
+	// err is the wrapped error, kept for errors.Is/As and logs but never serialized.
+	err error
 }
 
 func (e requestError) Code() string {
@@ -24,9 +28,17 @@ func (e requestError) Code() string {
 }
 
 func (e requestError) Error() string {
+	if e.err != nil {
+		return fmt.Sprintf("Message: %s;Error Code: %s;Status: %d;Error: %v", e.ErrorMessage, e.ErrorCode, e.ErrorStatus, e.err)
+	}
 	return fmt.Sprintf("Message: %s;Error Code: %s;Status: %d", e.ErrorMessage, e.ErrorCode, e.ErrorStatus)
 }
 
+// Unwrap returns the error given with WithIncomingError.
+func (e requestError) Unwrap() error {
+	return e.err
+}
+
 func (e requestError) Status() int {
 	return e.ErrorStatus
 }
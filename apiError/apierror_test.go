@@ -0,0 +1,31 @@
+package apiError
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithIncomingError(t *testing.T) {
+	cause := fmt.Errorf("select order 42: %w", sql.ErrNoRows)
+	err := NewApiError("order not found", http.StatusNotFound, WithCode("order_not_found"), WithIncomingError(cause))
+
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.Equal(t, "order_not_found", err.Code())
+	require.Contains(t, err.Error(), "select order 42")
+
+	wrapped := fmt.Errorf("handling request: %w", err)
+	var reqErr RequestError
+	require.True(t, errors.As(wrapped, &reqErr))
+	require.Equal(t, http.StatusNotFound, reqErr.Status())
+
+	data, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+	require.NotContains(t, string(data), "select order 42")
+	require.JSONEq(t, `{"message":"order not found","error":"order_not_found","cause":"","causes":null,"status":404}`, string(data))
+}
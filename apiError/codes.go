@@ -0,0 +1,149 @@
+package apiError
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrorCode is a stable, machine readable error code clients can rely on,
+// along with the status it is returned with.
+type ErrorCode struct {
+	Name        string
+	Status      int
+	Description string
+}
+
+// New creates an error with this code and status.
+// An empty message is replaced by the status text.
+func (c ErrorCode) New(message string, options ...Option) RequestError {
+	if message == "" {
+		message = http.StatusText(c.Status)
+	}
+	return NewApiError(message, c.Status, append([]Option{WithCode(c.Name)}, options...)...)
+}
+
+var registry = struct {
+	sync.RWMutex
+	codes map[string]ErrorCode
+}{codes: make(map[string]ErrorCode)}
+
+// RegisterCode adds a domain code, like "order_not_found", to the registry so it is
+// listed by Codes. Registering a name twice fails, codes being unique across an app.
+func RegisterCode(name string, status int, description string) (ErrorCode, error) {
+	if name == "" {
+		return ErrorCode{}, fmt.Errorf("[apiError] error code name is empty")
+	}
+	if status < 400 || status > 599 {
+		return ErrorCode{}, fmt.Errorf("[apiError] error code %s has invalid status %d", name, status)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.codes[name]; ok {
+		return ErrorCode{}, fmt.Errorf("[apiError] error code %s is already registered", name)
+	}
+	code := ErrorCode{Name: name, Status: status, Description: description}
+	registry.codes[name] = code
+	return code, nil
+}
+
+// MustRegisterCode is RegisterCode panicking on error, to declare codes as package variables:
+//
+//	var ErrOrderNotFound = apiError.MustRegisterCode("order_not_found", http.StatusNotFound, "The order does not exist.")
+func MustRegisterCode(name string, status int, description string) ErrorCode {
+	code, err := RegisterCode(name, status, description)
+	if err != nil {
+		panic(err)
+	}
+	return code
+}
+
+// LookupCode returns the registered code called name.
+func LookupCode(name string) (ErrorCode, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	code, ok := registry.codes[name]
+	return code, ok
+}
+
+// Codes returns every registered code, sorted by status then name.
+func Codes() []ErrorCode {
+	registry.RLock()
+	codes := make([]ErrorCode, 0, len(registry.codes))
+	for _, code := range registry.codes {
+		codes = append(codes, code)
+	}
+	registry.RUnlock()
+
+	sort.Slice(codes, func(i, j int) bool {
+		if codes[i].Status != codes[j].Status {
+			return codes[i].Status < codes[j].Status
+		}
+		return codes[i].Name < codes[j].Name
+	})
+	return codes
+}
+
+// WriteCodes writes the registered codes as a Markdown table, to generate the error
+// documentation of an app from a small program importing its packages.
+func WriteCodes(w io.Writer) error {
+	var table strings.Builder
+	table.WriteString("| Code | Status | Description |\n|------|--------|-------------|\n")
+	for _, code := range Codes() {
+		description := strings.ReplaceAll(code.Description, "|", `\|`)
+		fmt.Fprintf(&table, "| `%s` | %d %s | %s |\n", code.Name, code.Status, http.StatusText(code.Status), description)
+	}
+	_, err := io.WriteString(w, table.String())
+	return err
+}
+
+// Codes of the predefined constructors.
+var (
+	CodeBadRequest          = MustRegisterCode("bad_request", http.StatusBadRequest, "The request is malformed.")
+	CodeValidationFailed    = MustRegisterCode("validation_failed", http.StatusBadRequest, "Some fields of the request are invalid.")
+	CodeUnauthorized        = MustRegisterCode("unauthorized", http.StatusUnauthorized, "Authentication is missing or invalid.")
+	CodeForbidden           = MustRegisterCode("forbidden", http.StatusForbidden, "The caller is not allowed to perform the operation.")
+	CodeNotFound            = MustRegisterCode("not_found", http.StatusNotFound, "The resource does not exist.")
+	CodeConflict            = MustRegisterCode("conflict", http.StatusConflict, "The request conflicts with the current state of the resource.")
+	CodeTooManyRequests     = MustRegisterCode("too_many_requests", http.StatusTooManyRequests, "The caller sent too many requests, retry later.")
+	CodeClientClosedRequest = MustRegisterCode("client_closed_request", StatusClientClosedRequest, "The client went away before the response was ready.")
+	CodeInternal            = MustRegisterCode("internal_error", http.StatusInternalServerError, "An unexpected error occurred.")
+	CodeServiceUnavailable  = MustRegisterCode("service_unavailable", http.StatusServiceUnavailable, "The service is temporarily unavailable, retry later.")
+	CodeTimeout             = MustRegisterCode("timeout", http.StatusGatewayTimeout, "The operation did not complete in time.")
+)
+
+func BadRequest(message string, options ...Option) RequestError {
+	return CodeBadRequest.New(message, options...)
+}
+
+func Unauthorized(message string, options ...Option) RequestError {
+	return CodeUnauthorized.New(message, options...)
+}
+
+func Forbidden(message string, options ...Option) RequestError {
+	return CodeForbidden.New(message, options...)
+}
+
+func NotFound(message string, options ...Option) RequestError {
+	return CodeNotFound.New(message, options...)
+}
+
+func Conflict(message string, options ...Option) RequestError {
+	return CodeConflict.New(message, options...)
+}
+
+func TooManyRequests(message string, options ...Option) RequestError {
+	return CodeTooManyRequests.New(message, options...)
+}
+
+func Internal(message string, options ...Option) RequestError {
+	return CodeInternal.New(message, options...)
+}
+
+func ServiceUnavailable(message string, options ...Option) RequestError {
+	return CodeServiceUnavailable.New(message, options...)
+}
@@ -0,0 +1,49 @@
+package apiError
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstructors(t *testing.T) {
+	err := NotFound("order not found")
+	require.Equal(t, "not_found", err.Code())
+	require.Equal(t, http.StatusNotFound, err.Status())
+
+	err = TooManyRequests("")
+	require.Equal(t, "too_many_requests", err.Code())
+	require.Equal(t, "Too Many Requests", err.Message())
+}
+
+// unregisterCode removes a code registered by a test, so the test can run again.
+func unregisterCode(t *testing.T, name string) {
+	t.Cleanup(func() {
+		registry.Lock()
+		defer registry.Unlock()
+		delete(registry.codes, name)
+	})
+}
+
+func TestRegisterCode(t *testing.T) {
+	unregisterCode(t, "test_out_of_credit")
+	code, err := RegisterCode("test_out_of_credit", http.StatusPaymentRequired, "The balance | credit is too low.")
+	require.NoError(t, err)
+	require.Equal(t, "test_out_of_credit", code.New("not enough credit").Code())
+
+	_, err = RegisterCode("test_out_of_credit", http.StatusConflict, "")
+	require.Error(t, err)
+	_, err = RegisterCode("test_ok", http.StatusOK, "")
+	require.Error(t, err)
+
+	found, ok := LookupCode("test_out_of_credit")
+	require.True(t, ok)
+	require.Equal(t, code, found)
+
+	var listing strings.Builder
+	require.NoError(t, WriteCodes(&listing))
+	require.Contains(t, listing.String(), "| `test_out_of_credit` | 402 Payment Required | The balance \\| credit is too low. |\n")
+	require.Contains(t, listing.String(), "| `not_found` | 404 Not Found | The resource does not exist. |\n")
+}
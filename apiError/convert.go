@@ -0,0 +1,48 @@
+package apiError
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrValidation marks invalid input, wrap it to report a bad request:
+//
+//	fmt.Errorf("%w: email is required", apiError.ErrValidation)
+var ErrValidation = errors.New("validation failed")
+
+// StatusClientClosedRequest is used when the client went away before the response was ready.
+const StatusClientClosedRequest = 499
+
+// FromError converts err into a RequestError.
+// If err, or any error it wraps, already is a RequestError it is returned as is,
+// which is how failures from other packages keep the status they report: a rest.HTTPError
+// becomes a 502 with a generic message, unless sent with rest.RequestWithUpstreamStatus.
+// Well known errors are mapped to their status: sql.ErrNoRows to 404, ErrValidation
+// and FieldErrors to 400, context.DeadlineExceeded to 504 and context.Canceled to 499.
+// Any other error becomes an internal server error.
+func FromError(err error) RequestError {
+	if err == nil {
+		return nil
+	}
+
+	var reqErr RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr
+	}
+
+	var fieldErrs FieldErrors
+	switch {
+	case errors.As(err, &fieldErrs):
+		return CodeValidationFailed.New(ErrValidation.Error(), WithFieldErrors(fieldErrs), WithIncomingError(err))
+	case errors.Is(err, sql.ErrNoRows):
+		return NotFound("", WithIncomingError(err))
+	case errors.Is(err, ErrValidation):
+		return CodeValidationFailed.New(err.Error(), WithIncomingError(err))
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout.New("", WithIncomingError(err))
+	case errors.Is(err, context.Canceled):
+		return CodeClientClosedRequest.New("client closed request", WithIncomingError(err))
+	}
+	return Internal("", WithIncomingError(err))
+}
@@ -1,7 +1,5 @@
 package apiError
 
-import "fmt"
-
 type optionFunc func(*requestError)
 
 type Option interface {
@@ -22,9 +20,20 @@ func NewApiError(message string, status int, options ...Option) RequestError {
 	return err
 }
 
+// WithIncomingError wraps err, which errors.Is and errors.As then find through the
+// RequestError. It is part of Error() for logs but not of the JSON sent to clients,
+// use WithCause to expose details.
 func WithIncomingError(err error) optionFunc {
 	return func(re *requestError) {
-		re.ErrorCode = fmt.Sprintf("%v", err)
+		re.err = err
+	}
+}
+
+// WithCode sets the machine readable code clients rely on, like "order_not_found".
+// Codes are stable: unlike messages they never change once published.
+func WithCode(code string) optionFunc {
+	return func(re *requestError) {
+		re.ErrorCode = code
 	}
 }
 
@@ -0,0 +1,131 @@
+package apiError
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/alabuta-source/toolkit"
+)
+
+// CorrelationIDHeader carries the id tying a response to the logs of the request.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+type correlationKey struct{}
+
+// CorrelationID returns the correlation id of the request handled by Handler or Recover.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// withCorrelationID keeps the correlation id sent by the client, or creates one,
+// and sets it on the response and in the request context.
+func withCorrelationID(w http.ResponseWriter, r *http.Request) *http.Request {
+	if CorrelationID(r.Context()) != "" {
+		return r
+	}
+
+	id := r.Header.Get(CorrelationIDHeader)
+	if id == "" {
+		id = toolkit.GenerateID()
+	}
+	w.Header().Set(CorrelationIDHeader, id)
+	return r.WithContext(context.WithValue(r.Context(), correlationKey{}, id))
+}
+
+// Write writes err, converted with FromError, as a JSON response in the
+// {message, error, cause, causes, status} format. Use WriteError to let the client
+// choose problem details instead. A status outside 400-599 is written as 500.
+func Write(w http.ResponseWriter, err error) error {
+	reqErr := withErrorStatus(FromError(err))
+	data, marshalErr := json.Marshal(legacyBody(reqErr))
+	if marshalErr != nil {
+		return marshalErr
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(reqErr.Status())
+	_, writeErr := w.Write(data)
+	return writeErr
+}
+
+// Handler is an http.Handler returning its error instead of writing it.
+// The error is converted with FromError and written with WriteError. When the
+// handler already started the response, it is logged with the correlation id instead.
+type Handler func(w http.ResponseWriter, r *http.Request) error
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r = withCorrelationID(w, r)
+	rw := &responseWriter{ResponseWriter: w}
+	err := h(rw, r)
+	switch {
+	case err == nil:
+	case rw.wroteHeader:
+		log.Printf("[apiError] error serving %s %s after the response started (correlation id %s): %v",
+			r.Method, r.URL.Path, CorrelationID(r.Context()), err)
+	default:
+		_ = WriteError(w, r, FromError(err))
+	}
+}
+
+// Recover turns the panics of next into 500 responses, logging them with the
+// correlation id of the request. http.ErrAbortHandler is left to the server.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = withCorrelationID(w, r)
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			log.Printf("[apiError] panic serving %s %s (correlation id %s): %v",
+				r.Method, r.URL.Path, CorrelationID(r.Context()), recovered)
+			if !rw.wroteHeader {
+				_ = WriteError(w, r, Internal(""))
+			}
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// responseWriter remembers whether the response was started. It forwards Flush and
+// Hijack so streaming and websocket handlers keep working behind Handler and Recover.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseWriter) Flush() {
+	w.wroteHeader = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,102 @@
+package apiError
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("loading order: %w", sql.ErrNoRows), http.StatusNotFound},
+		{fmt.Errorf("%w: email is required", ErrValidation), http.StatusBadRequest},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{context.Canceled, StatusClientClosedRequest},
+		{fmt.Errorf("charging: %w", NewApiError("card declined", http.StatusPaymentRequired)), http.StatusPaymentRequired},
+		{fmt.Errorf("boom"), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.status, FromError(test.err).Status(), test.err.Error())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := Recover(Handler(func(w http.ResponseWriter, r *http.Request) error {
+		require.Equal(t, "abc-123", CorrelationID(r.Context()))
+		return fmt.Errorf("%w: email is required", ErrValidation)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set(CorrelationIDHeader, "abc-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "abc-123", rec.Header().Get(CorrelationIDHeader))
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, "validation failed: email is required", body["message"])
+}
+
+func TestRecover(t *testing.T) {
+	handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("nil map")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.NotEmpty(t, rec.Header().Get(CorrelationIDHeader))
+	require.NotContains(t, rec.Body.String(), "nil map")
+}
+
+func TestHandler_flush(t *testing.T) {
+	handler := Recover(Handler(func(w http.ResponseWriter, r *http.Request) error {
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+		_, _ = w.Write([]byte("data: 1\n\n"))
+		flusher.Flush()
+
+		_, ok = w.(http.Hijacker)
+		require.True(t, ok)
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	require.True(t, rec.Flushed)
+	require.Equal(t, "data: 1\n\n", rec.Body.String())
+}
+
+func TestHandler_errorAfterWrite(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	handler := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("partial"))
+		return errors.New("stream broken")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/export", nil)
+	req.Header.Set(CorrelationIDHeader, "abc-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, "partial", rec.Body.String())
+	require.Contains(t, logs.String(), "correlation id abc-123")
+	require.Contains(t, logs.String(), "stream broken")
+}
@@ -0,0 +1,216 @@
+package apiError
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object.
+// Extensions holds the members beyond the standard ones, like "code" or "causes".
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+len(problemMembers))
+	for name, value := range p.Extensions {
+		members[name] = value
+	}
+	for _, name := range problemMembers {
+		delete(members, name)
+	}
+
+	members["type"] = p.problemType()
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	standard := map[string]any{
+		"type": &p.Type, "title": &p.Title, "status": &p.Status, "detail": &p.Detail, "instance": &p.Instance,
+	}
+	p.Extensions = nil
+	for name, raw := range members {
+		if target, ok := standard[name]; ok {
+			if err := json.Unmarshal(raw, target); err != nil {
+				return fmt.Errorf("problem member %q: %w", name, err)
+			}
+			continue
+		}
+
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]any)
+		}
+		p.Extensions[name] = value
+	}
+	return nil
+}
+
+// problemType defaults to "about:blank", meaning the problem is described by its status.
+func (p Problem) problemType() string {
+	if p.Type == "" {
+		return "about:blank"
+	}
+	return p.Type
+}
+
+// RequestError converts problem details received from another service, so they can be
+// handled, and forwarded, like the errors created with NewApiError.
+func (p *Problem) RequestError() RequestError {
+	err := requestError{ErrorMessage: p.Detail, ErrorStatus: p.Status}
+	if err.ErrorMessage == "" {
+		err.ErrorMessage = p.Title
+	}
+	if code, ok := p.Extensions["code"].(string); ok {
+		err.ErrorCode = code
+	} else if p.Type != "" && p.Type != "about:blank" {
+		err.ErrorCode = p.Type
+	}
+	if cause, ok := p.Extensions["cause"].(string); ok {
+		err.Cause = cause
+	}
+	if causes, ok := p.Extensions["causes"].([]any); ok {
+		err.Causes = causes
+	}
+	return err
+}
+
+// ParseProblem decodes an application/problem+json document.
+func ParseProblem(data []byte) (*Problem, error) {
+	var problem Problem
+	if err := json.Unmarshal(data, &problem); err != nil {
+		return nil, fmt.Errorf("invalid problem details: %w", err)
+	}
+	return &problem, nil
+}
+
+// ToProblem converts err to problem details. The title is the status text, the detail
+// the error message, and the code, cause, causes and field errors become extension members.
+func ToProblem(err RequestError) *Problem {
+	problem := &Problem{
+		Title:  http.StatusText(err.Status()),
+		Status: err.Status(),
+		Detail: err.Message(),
+	}
+	extensions := make(map[string]any)
+	if code := err.Code(); code != "" {
+		extensions["code"] = code
+	}
+	if reqErr, ok := err.(requestError); ok {
+		if reqErr.Cause != "" {
+			extensions["cause"] = reqErr.Cause
+		}
+		if len(reqErr.Causes) > 0 {
+			extensions["causes"] = reqErr.Causes
+		}
+		if len(reqErr.Errors) > 0 {
+			extensions["errors"] = reqErr.Errors
+		}
+	}
+	if len(extensions) > 0 {
+		problem.Extensions = extensions
+	}
+	return problem
+}
+
+// WriteError writes err as the response to r, as problem details when the client
+// accepts application/problem+json at least as much as application/json,
+// otherwise in the {message, error, cause, causes, status} format.
+// A status outside 400-599 is written as 500.
+func WriteError(w http.ResponseWriter, r *http.Request, err RequestError) error {
+	err = withErrorStatus(err)
+	var (
+		body        any
+		contentType string
+	)
+	if acceptsProblem(r.Header.Get("Accept")) {
+		body, contentType = ToProblem(err), ProblemContentType
+	} else {
+		body, contentType = legacyBody(err), "application/json"
+	}
+
+	data, marshalErr := json.Marshal(body)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(err.Status())
+	_, writeErr := w.Write(data)
+	return writeErr
+}
+
+// withErrorStatus replaces a status outside 400-599, like the 0 of a problem without
+// status, with 500 so it is never written as a success or an invalid status.
+func withErrorStatus(err RequestError) RequestError {
+	if status := err.Status(); status >= 400 && status <= 599 {
+		return err
+	}
+	reqErr := legacyBody(err)
+	reqErr.ErrorStatus = http.StatusInternalServerError
+	return reqErr
+}
+
+func legacyBody(err RequestError) requestError {
+	if reqErr, ok := err.(requestError); ok {
+		return reqErr
+	}
+	return requestError{ErrorMessage: err.Message(), ErrorCode: err.Code(), ErrorStatus: err.Status()}
+}
+
+// acceptsProblem compares the quality values the Accept header gives to problem details and plain JSON.
+func acceptsProblem(accept string) bool {
+	problem, plain := 0.0, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case ProblemContentType:
+			problem = max(problem, q)
+		case "application/json":
+			plain = max(plain, q)
+		}
+	}
+	return problem > 0 && problem >= plain
+}
@@ -0,0 +1,79 @@
+package apiError
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToProblem(t *testing.T) {
+	err := requestError{ErrorMessage: "order not found", ErrorCode: "order_not_found", Cause: "order 42 was deleted", ErrorStatus: http.StatusNotFound}
+
+	data, marshalErr := json.Marshal(ToProblem(err))
+	require.NoError(t, marshalErr)
+	require.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Not Found",
+		"status": 404,
+		"detail": "order not found",
+		"code": "order_not_found",
+		"cause": "order 42 was deleted"
+	}`, string(data))
+}
+
+func TestParseProblem(t *testing.T) {
+	problem, err := ParseProblem([]byte(`{
+		"type": "https://example.com/probs/out-of-credit",
+		"title": "You do not have enough credit.",
+		"status": 403,
+		"detail": "Your current balance is 30, but that costs 50.",
+		"instance": "/account/12345/msgs/abc",
+		"balance": 30
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, 403, problem.Status)
+	require.Equal(t, "/account/12345/msgs/abc", problem.Instance)
+	require.Equal(t, map[string]any{"balance": float64(30)}, problem.Extensions)
+
+	reqErr := problem.RequestError()
+	require.Equal(t, 403, reqErr.Status())
+	require.Equal(t, "https://example.com/probs/out-of-credit", reqErr.Code())
+	require.Equal(t, "Your current balance is 30, but that costs 50.", reqErr.Message())
+}
+
+func TestWriteError(t *testing.T) {
+	err := NewApiError("invalid email", http.StatusBadRequest)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/json;q=0.9, application/problem+json")
+	rec := httptest.NewRecorder()
+	require.NoError(t, WriteError(rec, req, err))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid email"}`, rec.Body.String())
+
+	req.Header.Set("Accept", "application/json")
+	rec = httptest.NewRecorder()
+	require.NoError(t, WriteError(rec, req, err))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"message":"invalid email","error":"","cause":"","causes":null,"status":400}`, rec.Body.String())
+}
+
+func TestWriteError_invalidStatus(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", ProblemContentType)
+	for _, err := range []RequestError{NewApiError("x", 0), NewApiError("x", http.StatusOK), (&Problem{Detail: "x"}).RequestError()} {
+		rec := httptest.NewRecorder()
+		require.NoError(t, WriteError(rec, req, err))
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		require.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"x"}`, rec.Body.String())
+	}
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, Write(rec, NewApiError("x", 302)))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.JSONEq(t, `{"message":"x","error":"","cause":"","causes":null,"status":500}`, rec.Body.String())
+}
@@ -0,0 +1,269 @@
+package apiError
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alabuta-source/toolkit"
+)
+
+// FieldError is the violation of a rule by a field of the request.
+// Field is the path of the field as sent by the client, like "address.zip_code" or "items[0].quantity".
+type FieldError struct {
+	Field   string         `json:"field"`
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Params  map[string]any `json:"params,omitempty"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// FieldErrors is a list of violations, which errors.Is reports as an ErrValidation.
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Error()
+	}
+	return ErrValidation.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e FieldErrors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// WithFieldErrors sets the invalid fields of a validation failure.
+func WithFieldErrors(errs FieldErrors) optionFunc {
+	return func(re *requestError) {
+		re.Errors = errs
+	}
+}
+
+// Validation accumulates the violations found while checking a request.
+//
+//	v := apiError.NewValidation()
+//	v.Check(req.Quantity > 0, "quantity", "positive", "must be positive")
+//	if err := v.Err(); err != nil {
+//		return err
+//	}
+type Validation struct {
+	errors FieldErrors
+}
+
+func NewValidation() *Validation {
+	return &Validation{}
+}
+
+// Add records a violation of field.
+func (v *Validation) Add(field, code, message string, params map[string]any) *Validation {
+	v.errors = append(v.errors, FieldError{Field: field, Code: code, Message: message, Params: params})
+	return v
+}
+
+// Check records a violation of field when ok is false.
+func (v *Validation) Check(ok bool, field, code, message string) *Validation {
+	if !ok {
+		v.Add(field, code, message, nil)
+	}
+	return v
+}
+
+func (v *Validation) HasErrors() bool {
+	return len(v.errors) > 0
+}
+
+func (v *Validation) Errors() FieldErrors {
+	return v.errors
+}
+
+// Err returns a validation_failed error listing the violations, nil when there are none.
+func (v *Validation) Err() RequestError {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return CodeValidationFailed.New(ErrValidation.Error(), WithFieldErrors(v.errors), WithIncomingError(v.errors))
+}
+
+// Validate checks the fields of the struct v, or pointer to it, against their validate tag:
+//
+//	type SignUp struct {
+//		Email    string `json:"email" validate:"required,email"`
+//		Password string `json:"password" validate:"required,min=8,password"`
+//	}
+//
+// The rules are required, email, password (see toolkit.IsValidPassword), min=n and max=n
+// (the length of strings, slices and maps, the value of numbers) and oneof=a b c.
+// Nested structs and slices of structs are validated too. Fields are named after their
+// json tag. Required is checked first, wherever it is in the tag. Empty strings, slices
+// and maps, and nil pointers, skip the other rules: they are optional unless required.
+// Numbers are always checked, so min=18 rejects 0. Validate returns nil when v is valid, otherwise the error of Validation.Err.
+// An unknown rule is a programming error and panics.
+func Validate(v any) RequestError {
+	validation := NewValidation()
+	validateValue(validation, reflect.ValueOf(v), "")
+	return validation.Err()
+}
+
+func validateValue(v *Validation, value reflect.Value, path string) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		validateStruct(v, value, path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			validateValue(v, value.Index(i), path+"["+strconv.Itoa(i)+"]")
+		}
+	default:
+	}
+}
+
+func validateStruct(v *Validation, value reflect.Value, path string) {
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+
+		fieldValue := value.Field(i)
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			validateField(v, fieldValue, name, tag)
+		}
+		validateValue(v, fieldValue, name)
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// validateField applies the rules of tag to value, stopping at the first one violated.
+func validateField(v *Validation, value reflect.Value, name, tag string) {
+	rules := strings.Split(tag, ",")
+	required := false
+	for i, rule := range rules {
+		rules[i] = strings.TrimSpace(rule)
+		required = required || rules[i] == "required"
+	}
+
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			if required {
+				v.Add(name, "required", "is required", nil)
+			}
+			return
+		}
+		value = value.Elem()
+	}
+	if required && value.IsZero() {
+		v.Add(name, "required", "is required", nil)
+		return
+	}
+	if isEmpty(value) {
+		// empty optional fields are valid
+		return
+	}
+
+	for _, rule := range rules {
+		rule, arg, _ := strings.Cut(rule, "=")
+		if rule == "required" {
+			continue
+		}
+		if fieldErr := checkRule(value, rule, arg); fieldErr != nil {
+			fieldErr.Field = name
+			v.errors = append(v.errors, *fieldErr)
+			return
+		}
+	}
+}
+
+// isEmpty tells whether value is an empty string, slice or map. Other kinds are never empty.
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	default:
+		return false
+	}
+}
+
+func checkRule(value reflect.Value, rule, arg string) *FieldError {
+	switch rule {
+	case "required":
+		if value.IsZero() {
+			return &FieldError{Code: "required", Message: "is required"}
+		}
+	case "email":
+		if !toolkit.IsValidEmailFormat(value.String()) {
+			return &FieldError{Code: "email", Message: "must be a valid email address"}
+		}
+	case "password":
+		if !toolkit.IsValidPassword(value.String()) {
+			return &FieldError{Code: "password", Message: "must contain upper and lower case letters, a number and a special character"}
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			panic(fmt.Sprintf("[apiError] invalid validation rule %s=%s", rule, arg))
+		}
+		size, unit := measure(value)
+		if (rule == "min" && size < limit) || (rule == "max" && size > limit) {
+			bound := map[string]string{"min": "at least", "max": "at most"}[rule]
+			return &FieldError{Code: rule, Message: fmt.Sprintf("must be %s %s%s", bound, arg, unit), Params: map[string]any{rule: limit}}
+		}
+	case "oneof":
+		options := strings.Fields(arg)
+		current := fmt.Sprint(value.Interface())
+		for _, option := range options {
+			if option == current {
+				return nil
+			}
+		}
+		return &FieldError{Code: "oneof", Message: "must be one of " + strings.Join(options, ", "), Params: map[string]any{"oneof": options}}
+	default:
+		panic(fmt.Sprintf("[apiError] unknown validation rule %q", rule))
+	}
+	return nil
+}
+
+// measure returns what min and max compare: a length with its unit, or a number.
+func measure(value reflect.Value) (float64, string) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), ""
+	default:
+		panic(fmt.Sprintf("[apiError] min and max do not apply to %s", value.Type()))
+	}
+}
@@ -0,0 +1,98 @@
+package apiError
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type address struct {
+	ZipCode string `json:"zip_code" validate:"required,min=5,max=9"`
+}
+
+type signUp struct {
+	Email    string    `json:"email" validate:"required,email"`
+	Password string    `json:"password" validate:"required,min=8,password"`
+	Plan     string    `json:"plan" validate:"oneof=free pro"`
+	Age      int       `json:"age" validate:"min=18"`
+	Nickname *string   `json:"nickname" validate:"max=3"`
+	Address  *address  `json:"address"`
+	Others   []address `json:"others"`
+}
+
+func TestValidate(t *testing.T) {
+	nickname := "ana_maria"
+	err := Validate(&signUp{
+		Email:    "not-an-email",
+		Password: "Short1!",
+		Plan:     "gold",
+		Age:      16,
+		Nickname: &nickname,
+		Address:  &address{},
+		Others:   []address{{ZipCode: "01310100"}, {ZipCode: "123"}},
+	})
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, err.Status())
+	require.Equal(t, "validation_failed", err.Code())
+	require.True(t, errors.Is(err, ErrValidation))
+
+	var fieldErrs FieldErrors
+	require.True(t, errors.As(err, &fieldErrs))
+	require.Equal(t, FieldErrors{
+		{Field: "email", Code: "email", Message: "must be a valid email address"},
+		{Field: "password", Code: "min", Message: "must be at least 8 characters", Params: map[string]any{"min": float64(8)}},
+		{Field: "plan", Code: "oneof", Message: "must be one of free, pro", Params: map[string]any{"oneof": []string{"free", "pro"}}},
+		{Field: "age", Code: "min", Message: "must be at least 18", Params: map[string]any{"min": float64(18)}},
+		{Field: "nickname", Code: "max", Message: "must be at most 3 characters", Params: map[string]any{"max": float64(3)}},
+		{Field: "address.zip_code", Code: "required", Message: "is required"},
+		{Field: "others[1].zip_code", Code: "min", Message: "must be at least 5 characters", Params: map[string]any{"min": float64(5)}},
+	}, fieldErrs)
+
+	require.Nil(t, Validate(signUp{Email: "ana@example.com", Password: "Str0ng!pass", Age: 30}))
+}
+
+func TestValidate_ruleOrder(t *testing.T) {
+	type account struct {
+		Email    string `json:"email" validate:"email,required"`
+		Password string `json:"password" validate:"min=8,required"`
+		Age      int    `json:"age" validate:"min=18"`
+		Phone    string `json:"phone" validate:"min=8"`
+	}
+
+	var fieldErrs FieldErrors
+	require.True(t, errors.As(Validate(account{}), &fieldErrs))
+	require.Equal(t, FieldErrors{
+		{Field: "email", Code: "required", Message: "is required"},
+		{Field: "password", Code: "required", Message: "is required"},
+		{Field: "age", Code: "min", Message: "must be at least 18", Params: map[string]any{"min": float64(18)}},
+	}, fieldErrs)
+}
+
+func TestValidation(t *testing.T) {
+	err := NewValidation().
+		Check(false, "quantity", "positive", "must be positive").
+		Add("sku", "unknown", "does not exist", map[string]any{"sku": "A1"}).
+		Err()
+
+	data, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+	require.JSONEq(t, `{
+		"message": "validation failed",
+		"error": "validation_failed",
+		"cause": "",
+		"causes": null,
+		"status": 400,
+		"errors": [
+			{"field": "quantity", "code": "positive", "message": "must be positive"},
+			{"field": "sku", "code": "unknown", "message": "does not exist", "params": {"sku": "A1"}}
+		]
+	}`, string(data))
+
+	require.Nil(t, NewValidation().Err())
+	fromErr := FromError(fmt.Errorf("creating order: %w", FieldErrors{{Field: "sku", Code: "required", Message: "is required"}}))
+	require.Equal(t, http.StatusBadRequest, fromErr.Status())
+}
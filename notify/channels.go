@@ -0,0 +1,173 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/alabuta-source/toolkit/gomail"
+)
+
+// TelegramSendFunc sends a Telegram message, the SendText method of bot.Client is one.
+type TelegramSendFunc func(ctx context.Context, chatID, text, parseMode string) error
+
+const (
+	// maxTelegramMessage is the length of a message, in UTF-16 code units as Telegram counts.
+	maxTelegramMessage = 4096
+	maxTelegramTitle   = 256
+)
+
+// Telegram sends alerts as HTML messages to every chat of chatIDs through send.
+// The title and the text are cut to fit in a single message.
+func Telegram(send TelegramSendFunc, chatIDs ...string) Notifier {
+	return NotifierFunc(func(ctx context.Context, alert Alert) error {
+		message := "<b>" + truncateEscaped(html.EscapeString(alert.subject()), maxTelegramTitle) + "</b>"
+		if text := alert.text(); text != "" {
+			message += "\n"
+			message += truncateEscaped(html.EscapeString(text), maxTelegramMessage-utf16Len(message))
+		}
+
+		var errs []error
+		for _, chatID := range chatIDs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := send(ctx, chatID, message, "HTML"); err != nil {
+				errs = append(errs, fmt.Errorf("[notify] telegram chat %s: %w", chatID, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// Email sends alerts with sender to every address of to.
+func Email(sender gomail.EmailSender, to ...string) Notifier {
+	return NotifierFunc(func(ctx context.Context, alert Alert) error {
+		var body strings.Builder
+		if alert.Message != "" {
+			body.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(alert.Message), "\n", "<br>") + "</p>")
+		}
+		if names := alert.fieldNames(); len(names) > 0 {
+			body.WriteString("<table>")
+			for _, name := range names {
+				body.WriteString("<tr><th align=\"left\">" + html.EscapeString(name) + "</th><td>" +
+					html.EscapeString(alert.Fields[name]) + "</td></tr>")
+			}
+			body.WriteString("</table>")
+		}
+
+		var errs []error
+		for _, address := range to {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			err := sender.SendEmail(address, gomail.WithSubject(alert.subject()), gomail.WithBody(body.String()))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("[notify] email to %s: %w", address, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// maxWebhookText is the content limit of Discord, Slack accepts much longer texts.
+const maxWebhookText = 2000
+
+type webhookOptions struct {
+	client *http.Client
+}
+
+type WebhookOption func(*webhookOptions)
+
+// WithHTTPClient sets the client posting to the webhook, a client with a 10s timeout by default.
+func WithHTTPClient(client *http.Client) WebhookOption {
+	return func(o *webhookOptions) {
+		o.client = client
+	}
+}
+
+// Webhook posts alerts to an incoming webhook url. The payload sets both "text" and
+// "content", which makes it accepted by Slack, Discord and the services compatible with them.
+func Webhook(url string, options ...WebhookOption) Notifier {
+	opts := webhookOptions{client: &http.Client{Timeout: 10 * time.Second}}
+	for _, o := range options {
+		o(&opts)
+	}
+
+	return NotifierFunc(func(ctx context.Context, alert Alert) error {
+		text := alert.subject()
+		if details := alert.text(); details != "" {
+			text += "\n" + details
+		}
+		text = truncate(text, maxWebhookText)
+
+		payload, err := json.Marshal(map[string]string{"text": text, "content": text})
+		if err != nil {
+			return fmt.Errorf("[notify] webhook: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("[notify] webhook: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := opts.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("[notify] webhook: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			return fmt.Errorf("[notify] webhook answered %d: %s", resp.StatusCode, body)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	})
+}
+
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit-1]) + "…"
+}
+
+// truncateEscaped cuts HTML escaped text to limit UTF-16 code units, never inside an entity.
+func truncateEscaped(text string, limit int) string {
+	if utf16Len(text) <= limit {
+		return text
+	}
+
+	end, length := 0, 0
+	for i, r := range text {
+		// keep room for the ellipsis
+		if length+utf16.RuneLen(r) > limit-1 {
+			end = i
+			break
+		}
+		length += utf16.RuneLen(r)
+	}
+	if i := strings.LastIndexByte(text[:end], '&'); i >= 0 && !strings.Contains(text[i:end], ";") {
+		end = i
+	}
+	return text[:end] + "…"
+}
+
+func utf16Len(text string) int {
+	n := 0
+	for _, r := range text {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
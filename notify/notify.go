@@ -0,0 +1,123 @@
+// Package notify sends operational alerts to people through several channels,
+// Telegram, email or chat webhooks, behind a single Notifier interface.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Severity tells how urgent an alert is.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "INFO"
+	case SeverityWarning:
+		return "WARNING"
+	case SeverityError:
+		return "ERROR"
+	case SeverityCritical:
+		return "CRITICAL"
+	default:
+		return fmt.Sprintf("SEVERITY(%d)", int(s))
+	}
+}
+
+// Alert is a notification sent to every channel of a Notifier.
+type Alert struct {
+	Severity Severity
+	Title    string
+	Message  string
+	// Fields holds extra details, like the service or the transaction id, shown sorted by name.
+	Fields map[string]string
+	// Key identifies repetitions of the same alert for Throttle,
+	// Title and Message are used when empty.
+	Key string
+}
+
+func (a Alert) key() string {
+	if a.Key != "" {
+		return a.Key
+	}
+	return a.Title + "\x00" + a.Message
+}
+
+// subject is the one line summary of the alert, like "[ERROR] payment failed".
+func (a Alert) subject() string {
+	return "[" + a.Severity.String() + "] " + a.Title
+}
+
+// text renders the alert as plain text, after its subject.
+func (a Alert) text() string {
+	var text strings.Builder
+	text.WriteString(a.Message)
+	for _, name := range a.fieldNames() {
+		if text.Len() > 0 {
+			text.WriteString("\n")
+		}
+		text.WriteString(name + ": " + a.Fields[name])
+	}
+	return text.String()
+}
+
+func (a Alert) fieldNames() []string {
+	names := make([]string, 0, len(a.Fields))
+	for name := range a.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Notifier delivers alerts. Implementations must be safe for concurrent use.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+type NotifierFunc func(ctx context.Context, alert Alert) error
+
+func (f NotifierFunc) Notify(ctx context.Context, alert Alert) error {
+	return f(ctx, alert)
+}
+
+// FanOut sends every alert to all notifiers concurrently. A failing notifier does not
+// prevent the others from being notified, the errors are joined.
+func FanOut(notifiers ...Notifier) Notifier {
+	return NotifierFunc(func(ctx context.Context, alert Alert) error {
+		errs := make([]error, len(notifiers))
+		var wg sync.WaitGroup
+		for i, n := range notifiers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = n.Notify(ctx, alert)
+			}()
+		}
+		wg.Wait()
+		return errors.Join(errs...)
+	})
+}
+
+// MinSeverity only hands n the alerts of at least severity min, e.g. to page
+// on Telegram for errors while everything goes to a chat channel.
+func MinSeverity(min Severity, n Notifier) Notifier {
+	return NotifierFunc(func(ctx context.Context, alert Alert) error {
+		if alert.Severity < min {
+			return nil
+		}
+		return n.Notify(ctx, alert)
+	})
+}
@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alabuta-source/toolkit/gomail"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (r *recorder) Notify(_ context.Context, alert Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func TestTelegram(t *testing.T) {
+	var sent []string
+	send := func(_ context.Context, chatID, message, parseMode string) error {
+		require.Equal(t, "HTML", parseMode)
+		sent = append(sent, chatID+": "+message)
+		return nil
+	}
+
+	err := Telegram(send, "1", "2").Notify(context.Background(), Alert{
+		Severity: SeverityError,
+		Title:    "refund <failed>",
+		Message:  "tx1 & tx2",
+		Fields:   map[string]string{"service": "payments"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"1: <b>[ERROR] refund &lt;failed&gt;</b>\ntx1 &amp; tx2\nservice: payments",
+		"2: <b>[ERROR] refund &lt;failed&gt;</b>\ntx1 &amp; tx2\nservice: payments",
+	}, sent)
+}
+
+func TestTelegram_truncate(t *testing.T) {
+	var sent string
+	send := func(_ context.Context, _, message, _ string) error {
+		sent = message
+		return nil
+	}
+
+	alert := Alert{Title: strings.Repeat("&", 300), Message: strings.Repeat("<😀", 3000)}
+	require.NoError(t, Telegram(send, "1").Notify(context.Background(), alert))
+	require.LessOrEqual(t, utf16Len(sent), maxTelegramMessage)
+
+	title, text, _ := strings.Cut(sent, "\n")
+	require.True(t, strings.HasSuffix(title, "&amp;…</b>"), title)
+	require.True(t, strings.HasSuffix(text, "&lt;😀…") || strings.HasSuffix(text, "😀…"), text[len(text)-20:])
+}
+
+type emailSender struct {
+	gomail.EmailSender
+	to []string
+}
+
+func (s *emailSender) SendEmail(to string, _ ...gomail.Option) error {
+	s.to = append(s.to, to)
+	return nil
+}
+
+func TestEmail(t *testing.T) {
+	sender := &emailSender{}
+	err := Email(sender, "ops@example.com", "cto@example.com").Notify(context.Background(), Alert{Title: "disk full"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"ops@example.com", "cto@example.com"}, sender.to)
+}
+
+func TestWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		require.Equal(t, "[WARNING] slow queries\np99 above 2s", payload["text"])
+		require.Equal(t, payload["text"], payload["content"])
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	alert := Alert{Severity: SeverityWarning, Title: "slow queries", Message: "p99 above 2s"}
+	require.NoError(t, Webhook(server.URL).Notify(context.Background(), alert))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer failing.Close()
+	require.ErrorContains(t, Webhook(failing.URL).Notify(context.Background(), alert), "403")
+}
+
+func TestFanOut(t *testing.T) {
+	all, critical := &recorder{}, &recorder{}
+	failure := errors.New("smtp down")
+	notifier := FanOut(
+		all,
+		MinSeverity(SeverityCritical, critical),
+		NotifierFunc(func(context.Context, Alert) error { return failure }),
+	)
+
+	err := notifier.Notify(context.Background(), Alert{Severity: SeverityError, Title: "a"})
+	require.ErrorIs(t, err, failure)
+	require.Len(t, all.alerts, 1)
+	require.Empty(t, critical.alerts)
+}
+
+func TestThrottle(t *testing.T) {
+	rec := &recorder{}
+	notifier := Throttle(50*time.Millisecond, rec)
+	ctx := context.Background()
+
+	alert := Alert{Title: "db unreachable"}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, notifier.Notify(ctx, alert))
+	}
+	require.NoError(t, notifier.Notify(ctx, Alert{Title: "queue backlog"}))
+	require.Len(t, rec.alerts, 2)
+
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, notifier.Notify(ctx, alert))
+	require.Len(t, rec.alerts, 3)
+	require.Equal(t, "2 times since last alert", rec.alerts[2].Fields["repeated"])
+}
+
+func TestThrottle_failedSend(t *testing.T) {
+	rec := &recorder{}
+	fail := true
+	notifier := Throttle(time.Minute, NotifierFunc(func(ctx context.Context, alert Alert) error {
+		if fail {
+			return errors.New("telegram unreachable")
+		}
+		return rec.Notify(ctx, alert)
+	}))
+	ctx := context.Background()
+
+	alert := Alert{Title: "db unreachable"}
+	require.Error(t, notifier.Notify(ctx, alert))
+	fail = false
+	require.NoError(t, notifier.Notify(ctx, alert))
+	require.NoError(t, notifier.Notify(ctx, alert))
+	require.Len(t, rec.alerts, 1)
+}
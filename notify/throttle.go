@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Throttle drops the repetitions of an alert, identified by its Key, sent within window
+// of the last one delivered. The next delivery once the window is over tells how many
+// repetitions were dropped, so a flapping check sends one alert per window at most.
+// Repetitions arriving while an alert is being sent are dropped too. A failed send
+// is not recorded, so the next repetition is sent.
+func Throttle(window time.Duration, n Notifier) Notifier {
+	return &throttle{next: n, window: window, seen: make(map[string]*throttled)}
+}
+
+type throttle struct {
+	next   Notifier
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]*throttled
+}
+
+type throttled struct {
+	sent    time.Time
+	dropped int
+	sending bool
+}
+
+func (t *throttle) Notify(ctx context.Context, alert Alert) error {
+	now := time.Now()
+	key := alert.key()
+
+	t.mu.Lock()
+	entry, ok := t.seen[key]
+	if ok && (entry.sending || now.Sub(entry.sent) < t.window) {
+		entry.dropped++
+		t.mu.Unlock()
+		return nil
+	}
+	if !ok {
+		t.prune(now)
+		entry = &throttled{}
+		t.seen[key] = entry
+	}
+	entry.sending = true
+	dropped := entry.dropped
+	t.mu.Unlock()
+
+	err := t.notify(ctx, alert, dropped)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry.sending = false
+	if err != nil {
+		if entry.sent.IsZero() && entry.dropped == 0 {
+			delete(t.seen, key)
+		}
+		return err
+	}
+	entry.sent = now
+	// repetitions dropped during the send are reported with the next delivery
+	entry.dropped -= dropped
+	return nil
+}
+
+func (t *throttle) notify(ctx context.Context, alert Alert, dropped int) error {
+	if dropped > 0 {
+		fields := make(map[string]string, len(alert.Fields)+1)
+		for name, value := range alert.Fields {
+			fields[name] = value
+		}
+		fields["repeated"] = strconv.Itoa(dropped) + " times since last alert"
+		alert.Fields = fields
+	}
+	return t.next.Notify(ctx, alert)
+}
+
+// prune forgets the alerts that were not repeated within the window, so the map
+// does not grow with every distinct alert ever sent.
+func (t *throttle) prune(now time.Time) {
+	if len(t.seen) < 1024 {
+		return
+	}
+	for key, entry := range t.seen {
+		if !entry.sending && now.Sub(entry.sent) >= t.window && entry.dropped == 0 {
+			delete(t.seen, key)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package rest
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRefreshBefore is how long before expiry cached tokens are refreshed.
+const defaultRefreshBefore = 30 * time.Second
+
+// Token is a credential sent in the Authorization header.
+type Token struct {
+	AccessToken string
+	// TokenType is the authorization scheme, Bearer when empty.
+	TokenType    string
+	RefreshToken string
+	// Expiry is when the token expires, zero for tokens that never expire.
+	Expiry time.Time
+}
+
+// Authorization returns the value of the Authorization header for the token.
+func (t *Token) Authorization() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+func (t *Token) expiresWithin(d time.Duration) bool {
+	return !t.Expiry.IsZero() && time.Now().Add(d).After(t.Expiry)
+}
+
+// TokenSource supplies the token used to authenticate requests.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc adapts a function into a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// WithTokenSource sets the Authorization header of every request from src.
+// When the server answers 401 the cached token, if any, is dropped so the next
+// request fetches a new one.
+func WithTokenSource(src TokenSource) ClientOption {
+	return WithMiddleware(TokenSourceMiddleware(src))
+}
+
+func TokenSourceMiddleware(src TokenSource) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			token, err := src.Token(req.Context())
+			if err != nil {
+				return nil, fmt.Errorf("[restClient] error getting token: %w", err)
+			}
+			req.Header.Set("Authorization", token.Authorization())
+
+			resp, err := next.Do(req)
+			if err == nil && resp.StatusCode == http.StatusUnauthorized {
+				if cache, ok := src.(*reuseTokenSource); ok {
+					cache.invalidate(token)
+				}
+			}
+			return resp, err
+		})
+	}
+}
+
+// StaticToken always supplies the same bearer token.
+func StaticToken(accessToken string) TokenSource {
+	token := &Token{AccessToken: accessToken}
+	return TokenSourceFunc(func(context.Context) (*Token, error) {
+		return token, nil
+	})
+}
+
+// BasicAuth supplies HTTP basic authentication credentials.
+func BasicAuth(username, password string) TokenSource {
+	token := basicToken(username, password)
+	return TokenSourceFunc(func(context.Context) (*Token, error) {
+		return token, nil
+	})
+}
+
+func basicToken(username, password string) *Token {
+	return &Token{
+		AccessToken: base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+		TokenType:   "Basic",
+	}
+}
+
+// ReuseTokenSource caches the tokens of src and only asks for a new one when the
+// cached token is about to expire, refreshBefore ahead of its expiry (30s if zero).
+// It is safe for concurrent use, concurrent callers share a single refresh.
+func ReuseTokenSource(src TokenSource, refreshBefore time.Duration) TokenSource {
+	if cache, ok := src.(*reuseTokenSource); ok {
+		return cache
+	}
+	if refreshBefore <= 0 {
+		refreshBefore = defaultRefreshBefore
+	}
+	return &reuseTokenSource{src: src, refreshBefore: refreshBefore}
+}
+
+type reuseTokenSource struct {
+	mu            sync.Mutex
+	src           TokenSource
+	refreshBefore time.Duration
+	token         *Token
+}
+
+func (s *reuseTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && !s.token.expiresWithin(s.refreshBefore) {
+		return s.token, nil
+	}
+
+	token, err := s.src.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+func (s *reuseTokenSource) invalidate(token *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = nil
+	}
+}
+
+// AuthStyle tells how client credentials are sent to the token endpoint.
+type AuthStyle int
+
+const (
+	// AuthStyleHeader sends the client id and secret with HTTP basic authentication.
+	AuthStyleHeader AuthStyle = iota
+	// AuthStyleParams sends them as client_id and client_secret body parameters.
+	AuthStyleParams
+)
+
+// OAuth2Config describes an OAuth2 token endpoint.
+type OAuth2Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	AuthStyle    AuthStyle
+	// EndpointParams are extra parameters sent to the token endpoint.
+	EndpointParams url.Values
+	// Encoder encodes the token request body, form-urlencoded by default.
+	// Some providers, like Efí, expect JSONCodec instead.
+	Encoder Encoder
+	// Client sends the token requests, a new client by default.
+	// It must not be the client authenticated with the resulting TokenSource.
+	Client *RestClient
+}
+
+// ClientCredentials fetches tokens with the client credentials grant and caches them
+// until shortly before they expire.
+func ClientCredentials(config OAuth2Config) TokenSource {
+	return ReuseTokenSource(TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		params := url.Values{"grant_type": {"client_credentials"}}
+		return config.fetch(ctx, params)
+	}), 0)
+}
+
+// RefreshToken exchanges the refresh token of token for new access tokens with the
+// refresh token grant, keeping track of rotated refresh tokens, and caches them until
+// shortly before they expire. token is served as is while it is still valid.
+func RefreshToken(config OAuth2Config, token *Token) TokenSource {
+	var mu sync.Mutex
+	current := token
+	cache := &reuseTokenSource{refreshBefore: defaultRefreshBefore, token: token}
+	cache.src = TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if current == nil || current.RefreshToken == "" {
+			return nil, errors.New("[restClient] no refresh token available")
+		}
+
+		params := url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {current.RefreshToken},
+		}
+		refreshed, err := config.fetch(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		if refreshed.RefreshToken == "" {
+			refreshed.RefreshToken = current.RefreshToken
+		}
+		current = refreshed
+		return refreshed, nil
+	})
+	return cache
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func (config OAuth2Config) fetch(ctx context.Context, params url.Values) (*Token, error) {
+	if len(config.Scopes) > 0 {
+		params.Set("scope", strings.Join(config.Scopes, " "))
+	}
+	for k, v := range config.EndpointParams {
+		params[k] = v
+	}
+
+	headers := map[string]string{"Accept": ContentTypeJSON}
+	switch config.AuthStyle {
+	case AuthStyleParams:
+		params.Set("client_id", config.ClientID)
+		params.Set("client_secret", config.ClientSecret)
+	default:
+		headers["Authorization"] = basicToken(url.QueryEscape(config.ClientID), url.QueryEscape(config.ClientSecret)).Authorization()
+	}
+
+	encoder := config.Encoder
+	if encoder == nil {
+		encoder = FormCodec{}
+	}
+	var body any = params
+	if _, isForm := encoder.(FormCodec); !isForm {
+		flat := make(map[string]string, len(params))
+		for k := range params {
+			flat[k] = params.Get(k)
+		}
+		body = flat
+	}
+
+	client := config.Client
+	if client == nil {
+		client = defaultTokenClient
+	}
+
+	req := NewRequest(config.TokenURL, http.MethodPost,
+		RequestWithBody(body),
+		RequestWithEncoder(encoder),
+		RequestWithHeaders(headers),
+	)
+	resp, err := Do[tokenResponse](ctx, client, req)
+	if err != nil {
+		return nil, fmt.Errorf("[restClient] error fetching token: %w", err)
+	}
+	if resp.Data.AccessToken == "" {
+		return nil, errors.New("[restClient] token endpoint returned no access_token")
+	}
+
+	token := &Token{
+		AccessToken:  resp.Data.AccessToken,
+		TokenType:    resp.Data.TokenType,
+		RefreshToken: resp.Data.RefreshToken,
+	}
+	if resp.Data.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(resp.Data.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+var defaultTokenClient = NewHttpClient()
@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCredentials(t *testing.T) {
+	var tokenCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/token" {
+			tokenCalls.Add(1)
+			id, secret, ok := r.BasicAuth()
+			require.True(t, ok)
+			require.Equal(t, "client", id)
+			require.Equal(t, "secret", secret)
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"abc","token_type":"bearer","expires_in":3600}`))
+			return
+		}
+
+		require.Equal(t, "Bearer abc", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient(WithTokenSource(ClientCredentials(OAuth2Config{
+		TokenURL:     server.URL + "/oauth/token",
+		ClientID:     "client",
+		ClientSecret: "secret",
+	})))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, client.ExecuteRequest(context.Background(), NewRequest(server.URL+"/v2/cob", http.MethodGet)))
+	}
+	require.Equal(t, int32(1), tokenCalls.Load())
+}
+
+func TestBasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "pass", pass)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient(WithTokenSource(BasicAuth("user", "pass")))
+	require.NoError(t, client.ExecuteRequest(context.Background(), NewRequest(server.URL, http.MethodGet)))
+}
@@ -0,0 +1,217 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alabuta-source/toolkit/rest"
+)
+
+const defaultBaseURL = "https://api.telegram.org"
+
+// Client calls the Telegram Bot API on behalf of a single bot.
+// It is safe for concurrent use.
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *rest.RestClient
+}
+
+type Option func(*Client)
+
+// WithBaseURL points the client to another Bot API server, like a local one or a test server.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithRestClient sets the rest client used to reach the API, so middlewares, rate limits
+// or a circuit breaker can be configured on it. Its base url is not used.
+// The bot token is part of every url: its middlewares must not log the url as is.
+func WithRestClient(client *rest.RestClient) Option {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// NewClient creates a Client for the bot identified by token.
+func NewClient(token string, options ...Option) *Client {
+	client := &Client{token: token, baseURL: defaultBaseURL}
+	for _, o := range options {
+		o(client)
+	}
+	if client.httpClient == nil {
+		client.httpClient = rest.NewHttpClient(rest.WithTimeout(30 * time.Second))
+	}
+	return client
+}
+
+// APIError is returned when Telegram rejects a call.
+type APIError struct {
+	Method      string
+	Code        int
+	Description string
+	// RetryAfter is set when the bot is being rate limited (Code 429).
+	RetryAfter time.Duration
+	// MigrateToChatID is set when a group was upgraded to a supergroup.
+	MigrateToChatID int64
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("[telegram] %s failed with code %d: %s", e.Method, e.Code, e.Description)
+}
+
+type apiResponse[T any] struct {
+	OK          bool   `json:"ok"`
+	Result      T      `json:"result"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+	Parameters  *struct {
+		RetryAfter      int   `json:"retry_after"`
+		MigrateToChatID int64 `json:"migrate_to_chat_id"`
+	} `json:"parameters"`
+}
+
+func (r *apiResponse[T]) err(method string) *APIError {
+	apiErr := &APIError{Method: method, Code: r.ErrorCode, Description: r.Description}
+	if r.Parameters != nil {
+		apiErr.RetryAfter = time.Duration(r.Parameters.RetryAfter) * time.Second
+		apiErr.MigrateToChatID = r.Parameters.MigrateToChatID
+	}
+	return apiErr
+}
+
+// params holds the parameters of a Bot API call.
+type params map[string]any
+
+// call invokes a Bot API method. Params holding an InputFile to upload are sent as
+// multipart/form-data, everything else is sent as JSON.
+func call[T any](ctx context.Context, c *Client, method string, p params, options ...rest.Option) (T, error) {
+	var zero T
+
+	body, err := p.body()
+	if err != nil {
+		return zero, err
+	}
+
+	req := rest.NewRequest(
+		c.baseURL+"/bot{token}/{method}",
+		http.MethodPost,
+		rest.RequestWithPathParams(map[string]string{"token": c.token, "method": method}),
+		body,
+		rest.RequestWithErrorDecodeValue(&apiResponse[json.RawMessage]{}),
+	).With(options...)
+
+	resp, err := rest.Do[apiResponse[T]](ctx, c.httpClient, req)
+	if err != nil {
+		var httpErr *rest.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Payload != nil {
+			return zero, httpErr.Payload.(*apiResponse[json.RawMessage]).err(method)
+		}
+		return zero, fmt.Errorf("[telegram] %s failed: %w", method, c.redact(err))
+	}
+	if !resp.Data.OK {
+		return zero, resp.Data.err(method)
+	}
+	return resp.Data.Result, nil
+}
+
+// redact removes the bot token, part of the request url, from err.
+// An HTTPError is copied with a redacted URL, other errors only keep their message
+// and errors.Is matching, so the url they may hold can't be reached with errors.As.
+func (c *Client) redact(err error) error {
+	var httpErr *rest.HTTPError
+	if errors.As(err, &httpErr) {
+		redacted := *httpErr
+		redacted.URL = c.redactText(httpErr.URL)
+		return &redacted
+	}
+	return &redactedError{message: c.redactText(err.Error()), err: err}
+}
+
+func (c *Client) redactText(text string) string {
+	if c.token == "" {
+		return text
+	}
+	text = strings.ReplaceAll(text, c.token, "[REDACTED]")
+	return strings.ReplaceAll(text, url.PathEscape(c.token), "[REDACTED]")
+}
+
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+func (p params) body() (rest.Option, error) {
+	var files []rest.MultipartFile
+	for name, value := range p {
+		if file, ok := value.(InputFile); ok && file.Reader != nil {
+			files = append(files, rest.MultipartFile{Field: name, FileName: file.FileName, Content: file.Reader})
+		}
+	}
+	if len(files) == 0 {
+		return rest.RequestWithBody(p), nil
+	}
+
+	fields := make(map[string]string, len(p))
+	for name, value := range p {
+		switch v := value.(type) {
+		case InputFile:
+			if v.Reader == nil {
+				fields[name] = v.ID
+			}
+		case string:
+			fields[name] = v
+		case int64:
+			fields[name] = strconv.FormatInt(v, 10)
+		case bool:
+			fields[name] = strconv.FormatBool(v)
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("[telegram] error encoding %s: %v", name, err)
+			}
+			fields[name] = string(data)
+		}
+	}
+	return rest.RequestWithMultipart(rest.MultipartBody{Fields: fields, Files: files}), nil
+}
+
+// InputFile is a file sent to Telegram: either an upload, read from Reader,
+// or a file already known by Telegram, given by ID as a file_id or an HTTP url.
+type InputFile struct {
+	FileName string
+	Reader   io.Reader
+	ID       string
+}
+
+// FileUpload uploads the content of reader under fileName.
+func FileUpload(fileName string, reader io.Reader) InputFile {
+	return InputFile{FileName: fileName, Reader: reader}
+}
+
+// FileID references a file already stored by Telegram or reachable by url.
+func FileID(id string) InputFile {
+	return InputFile{ID: id}
+}
+
+func (f InputFile) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.ID)
+}
@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alabuta-source/toolkit/rest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_SendMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/bot123:abc/sendMessage", r.URL.Path)
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "42", body["chat_id"])
+		require.Equal(t, "deploy & rollback?", body["text"])
+		require.Equal(t, true, body["disable_notification"])
+		require.NotNil(t, body["reply_markup"])
+
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":7,"chat":{"id":42,"type":"private"},"text":"deploy & rollback?"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("123:abc", WithBaseURL(server.URL))
+	msg, err := client.SendMessage(context.Background(), "42", "deploy & rollback?",
+		WithDisableNotification(true),
+		WithInlineKeyboard(NewInlineKeyboard(NewInlineKeyboardRow(CallbackButton("Rollback", "rollback:1")))),
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), msg.MessageID)
+	require.Equal(t, int64(42), msg.Chat.ID)
+}
+
+func TestClient_SendDocument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		require.Equal(t, "42", r.FormValue("chat_id"))
+		require.Equal(t, "daily report", r.FormValue("caption"))
+
+		file, header, err := r.FormFile("document")
+		require.NoError(t, err)
+		content, _ := io.ReadAll(file)
+		require.Equal(t, "report.csv", header.Filename)
+		require.Equal(t, "a,b", string(content))
+
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":8,"chat":{"id":42},"document":{"file_id":"f1","file_unique_id":"u1"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("token", WithBaseURL(server.URL))
+	msg, err := client.SendDocument(context.Background(), "42", FileUpload("report.csv", strings.NewReader("a,b")), "daily report")
+	require.NoError(t, err)
+	require.Equal(t, "f1", msg.Document.FileID)
+}
+
+func TestClient_apiError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 5","parameters":{"retry_after":5}}`))
+	}))
+	defer server.Close()
+
+	err := NewClient("token", WithBaseURL(server.URL)).DeleteMessage(context.Background(), "42", 1)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, 429, apiErr.Code)
+	require.Equal(t, 5*time.Second, apiErr.RetryAfter)
+}
+
+func TestClient_redactToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("<html>bad gateway</html>"))
+	}))
+
+	client := NewClient("123:secret", WithBaseURL(server.URL))
+	err := client.DeleteMessage(context.Background(), "42", 1)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "secret")
+	var httpErr *rest.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+	require.NotContains(t, httpErr.URL, "secret")
+
+	server.Close()
+	err = client.DeleteMessage(context.Background(), "42", 1)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "secret")
+	var urlErr *url.Error
+	require.False(t, errors.As(err, &urlErr))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, client.DeleteMessage(ctx, "42", 1), context.Canceled)
+}
+
+func TestEnvClient(t *testing.T) {
+	t.Setenv("BOT_TOKEN", "123:abc")
+	client := envClient()
+	require.Same(t, client, envClient())
+
+	t.Setenv("BOT_TOKEN", "456:def")
+	require.NotSame(t, client, envClient())
+	require.Equal(t, "456:def", envClient().token)
+}
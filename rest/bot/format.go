@@ -0,0 +1,323 @@
+package bot
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MaxMessageLength is the maximum length of a text message, in UTF-16 code units
+// as Telegram counts: most emojis take two.
+const MaxMessageLength = 4096
+
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+var (
+	markdownV2CodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+	markdownV2URLEscaper  = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+)
+
+// EscapeMarkdownV2 escapes text so it is shown as is in a MarkdownV2 message.
+func EscapeMarkdownV2(text string) string {
+	return markdownV2Escaper.Replace(text)
+}
+
+// EscapeHTML escapes text so it is shown as is in an HTML message.
+func EscapeHTML(text string) string {
+	return html.EscapeString(text)
+}
+
+// MessageBuilder builds a formatted message, escaping every piece of content it is given.
+// Messages longer than MaxMessageLength are split by Messages without breaking the formatting.
+type MessageBuilder struct {
+	parseMode string
+	segments  []segment
+}
+
+// segment is a piece of the message, rendered from its unescaped text.
+// Splittable segments can be cut in several pieces, each rendered on its own.
+type segment struct {
+	text       string
+	render     func(text string) string
+	splittable bool
+}
+
+// NewMarkdownV2Message creates a builder of MarkdownV2ParseMode messages.
+func NewMarkdownV2Message() *MessageBuilder {
+	return &MessageBuilder{parseMode: MarkdownV2ParseMode}
+}
+
+// NewHTMLMessage creates a builder of HTMLParseMode messages.
+func NewHTMLMessage() *MessageBuilder {
+	return &MessageBuilder{parseMode: HTMLParseMode}
+}
+
+// ParseMode returns the parse mode to send the message with.
+func (b *MessageBuilder) ParseMode() string {
+	return b.parseMode
+}
+
+func (b *MessageBuilder) html() bool {
+	return b.parseMode == HTMLParseMode
+}
+
+func (b *MessageBuilder) add(text string, splittable bool, render func(string) string) *MessageBuilder {
+	b.segments = append(b.segments, segment{text: text, render: render, splittable: splittable})
+	return b
+}
+
+func (b *MessageBuilder) wrap(text, markdown, tag string) *MessageBuilder {
+	if b.html() {
+		return b.add(text, true, func(s string) string {
+			return "<" + tag + ">" + EscapeHTML(s) + "</" + tag + ">"
+		})
+	}
+	return b.add(text, true, func(s string) string {
+		return markdown + EscapeMarkdownV2(s) + markdown
+	})
+}
+
+// Text appends plain text.
+func (b *MessageBuilder) Text(text string) *MessageBuilder {
+	if b.html() {
+		return b.add(text, true, EscapeHTML)
+	}
+	return b.add(text, true, EscapeMarkdownV2)
+}
+
+// Line appends text followed by a line break.
+func (b *MessageBuilder) Line(text string) *MessageBuilder {
+	return b.Text(text + "\n")
+}
+
+func (b *MessageBuilder) Bold(text string) *MessageBuilder {
+	return b.wrap(text, "*", "b")
+}
+
+func (b *MessageBuilder) Italic(text string) *MessageBuilder {
+	return b.wrap(text, "_", "i")
+}
+
+func (b *MessageBuilder) Strikethrough(text string) *MessageBuilder {
+	return b.wrap(text, "~", "s")
+}
+
+// Code appends inline monospace text.
+func (b *MessageBuilder) Code(text string) *MessageBuilder {
+	if b.html() {
+		return b.add(text, true, func(s string) string {
+			return "<code>" + EscapeHTML(s) + "</code>"
+		})
+	}
+	return b.add(text, true, func(s string) string {
+		return "`" + markdownV2CodeEscaper.Replace(s) + "`"
+	})
+}
+
+// CodeBlock appends a preformatted block, highlighted as language when not empty.
+func (b *MessageBuilder) CodeBlock(language, code string) *MessageBuilder {
+	if b.html() {
+		open, closing := "<pre>", "</pre>\n"
+		if language != "" {
+			open = `<pre><code class="language-` + EscapeHTML(language) + `">`
+			closing = "</code></pre>\n"
+		}
+		return b.add(code, true, func(s string) string {
+			return open + EscapeHTML(strings.TrimSuffix(s, "\n")) + closing
+		})
+	}
+	return b.add(code, true, func(s string) string {
+		return "```" + markdownV2CodeEscaper.Replace(language) + "\n" + markdownV2CodeEscaper.Replace(strings.TrimSuffix(s, "\n")) + "\n```\n"
+	})
+}
+
+// Link appends text pointing to url.
+func (b *MessageBuilder) Link(text, url string) *MessageBuilder {
+	if b.html() {
+		return b.add(text, false, func(s string) string {
+			return `<a href="` + EscapeHTML(url) + `">` + EscapeHTML(s) + "</a>"
+		})
+	}
+	return b.add(text, false, func(s string) string {
+		return "[" + EscapeMarkdownV2(s) + "](" + markdownV2URLEscaper.Replace(url) + ")"
+	})
+}
+
+// Mention appends text mentioning the user, which works for users without a username.
+func (b *MessageBuilder) Mention(text string, userID int64) *MessageBuilder {
+	return b.Link(text, "tg://user?id="+strconv.FormatInt(userID, 10))
+}
+
+// Table appends rows as a preformatted block with aligned columns, the first row
+// being the header. Telegram has no tables, a monospace block is the closest rendering.
+func (b *MessageBuilder) Table(rows [][]string) *MessageBuilder {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var table strings.Builder
+	line := func(row []string) {
+		for i, cell := range row {
+			if i > 0 {
+				table.WriteString(" | ")
+			}
+			table.WriteString(cell)
+			if i < len(row)-1 {
+				table.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+			}
+		}
+		table.WriteString("\n")
+	}
+	for i, row := range rows {
+		line(row)
+		if i == 0 && len(rows) > 1 {
+			separators := make([]string, len(widths))
+			for j, width := range widths {
+				separators[j] = strings.Repeat("-", width)
+			}
+			line(separators)
+		}
+	}
+	return b.CodeBlock("", table.String())
+}
+
+// String renders the whole message, regardless of its length.
+func (b *MessageBuilder) String() string {
+	var message strings.Builder
+	for _, s := range b.segments {
+		message.WriteString(s.render(s.text))
+	}
+	return message.String()
+}
+
+// Messages renders the message split in parts of at most MaxMessageLength UTF-16 code units.
+// Parts are cut between segments when possible, otherwise inside a segment at a line
+// break or a space, each part of a segment keeping its formatting. The length is measured
+// on the formatted text, which is never shorter than what Telegram counts.
+func (b *MessageBuilder) Messages() []string {
+	return b.split(MaxMessageLength)
+}
+
+func (b *MessageBuilder) split(limit int) []string {
+	var (
+		messages []string
+		current  strings.Builder
+		length   int
+	)
+	flush := func() {
+		if length > 0 {
+			messages = append(messages, current.String())
+			current.Reset()
+			length = 0
+		}
+	}
+	for _, s := range b.segments {
+		rendered := s.render(s.text)
+		n := utf16Len(rendered)
+		if length+n <= limit {
+			current.WriteString(rendered)
+			length += n
+			continue
+		}
+
+		flush()
+		if n <= limit || !s.splittable {
+			current.WriteString(rendered)
+			length = n
+			continue
+		}
+		pieces := splitSegment(s, limit)
+		messages = append(messages, pieces[:len(pieces)-1]...)
+		current.WriteString(pieces[len(pieces)-1])
+		length = utf16Len(pieces[len(pieces)-1])
+	}
+	flush()
+	return messages
+}
+
+// splitSegment renders s in pieces of at most limit UTF-16 code units.
+func splitSegment(s segment, limit int) []string {
+	overhead := utf16Len(s.render(""))
+	var pieces []string
+	for remaining := s.text; remaining != ""; {
+		budget := max(limit-overhead, 1)
+		for {
+			text := cutText(remaining, budget)
+			rendered := s.render(text)
+			// escaping makes the rendered text longer, retry with a smaller budget
+			if n := utf16Len(rendered); n > limit && budget > 1 {
+				budget = max(budget-(n-limit), 1)
+				continue
+			}
+			pieces = append(pieces, rendered)
+			remaining = remaining[len(text):]
+			break
+		}
+	}
+	return pieces
+}
+
+// cutText returns the start of text up to budget UTF-16 code units, cut after the last
+// line break or space of its second half when there is one.
+func cutText(text string, budget int) string {
+	if utf16Len(text) <= budget {
+		return text
+	}
+
+	end, length := 0, 0
+	for i, r := range text {
+		if length+utf16.RuneLen(r) > budget {
+			end = i
+			break
+		}
+		length += utf16.RuneLen(r)
+	}
+	if end == 0 {
+		// a character longer than the budget still makes progress
+		_, end = utf8.DecodeRuneInString(text)
+	}
+	for _, sep := range []string{"\n", " "} {
+		if i := strings.LastIndex(text[:end], sep); i >= end/2 {
+			return text[:i+1]
+		}
+	}
+	return text[:end]
+}
+
+// utf16Len returns the length of text in UTF-16 code units.
+func utf16Len(text string) int {
+	n := 0
+	for _, r := range text {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// SendFormattedMessage sends message to chatID, in several messages when it is too long.
+// It stops at the first part failing and returns the messages sent until then.
+func (c *Client) SendFormattedMessage(ctx context.Context, chatID string, message *MessageBuilder, options ...MessageOption) ([]*Message, error) {
+	options = append(options[:len(options):len(options)], WithParseMode(message.ParseMode()))
+
+	var sent []*Message
+	for _, text := range message.Messages() {
+		msg, err := c.SendMessage(ctx, chatID, text, options...)
+		if err != nil {
+			return sent, err
+		}
+		sent = append(sent, msg)
+	}
+	return sent, nil
+}
@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageBuilder_MarkdownV2(t *testing.T) {
+	msg := NewMarkdownV2Message().
+		Bold("Refund #42").
+		Text(" for user_name (1.5 BRL)!\n").
+		Mention("Ana", 7).
+		Text(" ").
+		Link("details", "https://example.com/tx?id=(1)").
+		Text("\n").
+		Code("a`b").
+		Text("\n").
+		CodeBlock("go", "x := `*`")
+
+	require.Equal(t,
+		"*Refund \\#42* for user\\_name \\(1\\.5 BRL\\)\\!\n"+
+			"[Ana](tg://user?id=7) [details](https://example.com/tx?id=(1\\))\n"+
+			"`a\\`b`\n"+
+			"```go\nx := \\`*\\`\n```\n",
+		msg.String())
+}
+
+func TestMessageBuilder_HTML(t *testing.T) {
+	msg := NewHTMLMessage().
+		Bold("<b>").
+		Text(" & ").
+		Link("a\"b", "https://example.com/?a=1&b=2").
+		Table([][]string{{"tx", "amount"}, {"tx1", "10"}})
+
+	require.Equal(t,
+		"<b>&lt;b&gt;</b> &amp; <a href=\"https://example.com/?a=1&amp;b=2\">a&#34;b</a>"+
+			"<pre>tx  | amount\n--- | ------\ntx1 | 10</pre>\n",
+		msg.String())
+}
+
+func TestMessageBuilder_Messages(t *testing.T) {
+	msg := NewMarkdownV2Message().Text("intro\n")
+	for i := 0; i < 300; i++ {
+		msg.Line("line with dots... and stars **")
+	}
+	msg.CodeBlock("", strings.Repeat("log line\n", 1000))
+
+	parts := msg.Messages()
+	require.Greater(t, len(parts), 3)
+	for _, part := range parts {
+		require.LessOrEqual(t, utf16Len(part), MaxMessageLength)
+	}
+	require.True(t, strings.HasPrefix(parts[0], "intro\n"))
+	last := parts[len(parts)-1]
+	require.True(t, strings.HasPrefix(last, "```\n"))
+	require.True(t, strings.HasSuffix(last, "\n```\n"))
+
+	require.Equal(t, []string{"short"}, NewHTMLMessage().Text("short").Messages())
+}
+
+func TestMessageBuilder_MessagesEmoji(t *testing.T) {
+	parts := NewHTMLMessage().Text(strings.Repeat("🚀 ", 3000)).Messages()
+	require.Len(t, parts, 3)
+	for _, part := range parts {
+		require.LessOrEqual(t, utf16Len(part), MaxMessageLength)
+		require.True(t, utf8.ValidString(part))
+	}
+	require.Equal(t, strings.Repeat("🚀 ", 3000), strings.Join(parts, ""))
+}
@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// Poller receives updates with getUpdates and hands them to a Router.
+type Poller struct {
+	client         *Client
+	router         *Router
+	timeout        time.Duration
+	retryDelay     time.Duration
+	allowedUpdates []string
+	onError        func(err error)
+}
+
+type PollerOption func(*Poller)
+
+// WithPollTimeout sets how long each getUpdates call waits for updates, 30s by default.
+func WithPollTimeout(timeout time.Duration) PollerOption {
+	return func(p *Poller) {
+		p.timeout = timeout
+	}
+}
+
+// WithAllowedUpdates restricts the update types received, e.g. "message", "callback_query".
+func WithAllowedUpdates(types ...string) PollerOption {
+	return func(p *Poller) {
+		p.allowedUpdates = types
+	}
+}
+
+// WithPollErrorHandler receives the errors of failed getUpdates calls, which are retried
+// after a short delay.
+func WithPollErrorHandler(fn func(err error)) PollerOption {
+	return func(p *Poller) {
+		p.onError = fn
+	}
+}
+
+func NewPoller(client *Client, router *Router, options ...PollerOption) *Poller {
+	poller := &Poller{
+		client:     client,
+		router:     router,
+		timeout:    30 * time.Second,
+		retryDelay: 3 * time.Second,
+	}
+	for _, o := range options {
+		o(poller)
+	}
+	return poller
+}
+
+// Run polls until ctx is done, dispatching updates one at a time in the order they
+// were received. Handler errors go to the Router OnError function and do not stop polling.
+func (p *Poller) Run(ctx context.Context) error {
+	var offset int64
+	for {
+		updates, err := p.client.GetUpdates(ctx, offset, 100, p.timeout, p.allowedUpdates...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if p.onError != nil {
+				p.onError(err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(p.retryDelay):
+			}
+			continue
+		}
+
+		for i := range updates {
+			_ = p.router.Dispatch(ctx, &updates[i])
+			offset = updates[i].UpdateID + 1
+		}
+	}
+}
+
+// WebhookHandler serves the updates pushed by Telegram to a webhook registered with
+// SetWebhook. Requests without the expected secret token are rejected with 401,
+// an empty secretToken disables the check.
+// Updates are acknowledged with 200 once dispatched, even when the handler fails,
+// so Telegram does not redeliver them; failures reach the Router OnError function.
+func WebhookHandler(router *Router, secretToken string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if secretToken != "" &&
+			subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), []byte(secretToken)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var update Update
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_ = router.Dispatch(r.Context(), &update)
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Dispatch(t *testing.T) {
+	var refunded []string
+	var pressed string
+	var fallbacks int
+	router := NewRouter().
+		Username("Shop_Bot").
+		Command("refund", func(ctx context.Context, msg *Message, args []string) error {
+			refunded = args
+			return nil
+		}).
+		Callback("rollback:", func(ctx context.Context, query *CallbackQuery) error {
+			pressed = "rollback"
+			return nil
+		}).
+		Callback("rollback:force:", func(ctx context.Context, query *CallbackQuery) error {
+			pressed = "force"
+			return nil
+		}).
+		Default(func(ctx context.Context, update *Update) error {
+			fallbacks++
+			return nil
+		})
+
+	ctx := context.Background()
+	require.NoError(t, router.Dispatch(ctx, &Update{Message: &Message{Text: "/refund@shop_bot tx1"}}))
+	require.Equal(t, []string{"tx1"}, refunded)
+	require.NoError(t, router.Dispatch(ctx, &Update{Message: &Message{Text: "/refund@other_bot tx2"}}))
+	require.Equal(t, []string{"tx1"}, refunded)
+	require.Equal(t, 1, fallbacks)
+	require.NoError(t, router.Dispatch(ctx, &Update{Message: &Message{Text: "/refund tx3"}}))
+	require.Equal(t, []string{"tx3"}, refunded)
+
+	require.NoError(t, router.Dispatch(ctx, &Update{CallbackQuery: &CallbackQuery{Data: "rollback:force:3"}}))
+	require.Equal(t, "force", pressed)
+	require.NoError(t, router.Dispatch(ctx, &Update{CallbackQuery: &CallbackQuery{Data: "rollback:3"}}))
+	require.Equal(t, "rollback", pressed)
+
+	require.NoError(t, router.Dispatch(ctx, &Update{Message: &Message{Text: "/unknown"}}))
+	require.Equal(t, 2, fallbacks)
+
+	anonymous := NewRouter().Command("refund", func(ctx context.Context, msg *Message, args []string) error {
+		refunded = args
+		return nil
+	})
+	require.NoError(t, anonymous.Dispatch(ctx, &Update{Message: &Message{Text: "/refund@shop_bot tx4"}}))
+	require.Equal(t, []string{"tx3"}, refunded)
+}
+
+func TestPoller_Run(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch calls.Add(1) {
+		case 1:
+			require.Equal(t, float64(0), body["offset"])
+			_, _ = w.Write([]byte(`{"ok":true,"result":[{"update_id":10,"message":{"message_id":1,"chat":{"id":42},"text":"/status"}}]}`))
+		default:
+			require.Equal(t, float64(11), body["offset"])
+			_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	router := NewRouter().Command("status", func(ctx context.Context, msg *Message, args []string) error {
+		require.Equal(t, int64(42), msg.Chat.ID)
+		return nil
+	})
+	poller := NewPoller(NewClient("token", WithBaseURL(server.URL)), router, WithPollTimeout(0))
+
+	done := make(chan error)
+	go func() { done <- poller.Run(ctx) }()
+	require.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, 10*time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWebhookHandler(t *testing.T) {
+	var received int64
+	router := NewRouter().Default(func(ctx context.Context, update *Update) error {
+		received = update.UpdateID
+		return nil
+	})
+	handler := WebhookHandler(router, "s3cret")
+
+	body := `{"update_id":5,"message":{"message_id":1,"chat":{"id":42},"text":"hi"}}`
+	req := httptest.NewRequest(http.MethodPost, "/telegram", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Zero(t, received)
+
+	req = httptest.NewRequest(http.MethodPost, "/telegram", strings.NewReader(body))
+	req.Header.Set("X-Telegram-Bot-Api-Secret-Token", "s3cret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, int64(5), received)
+}
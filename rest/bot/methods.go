@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+)
+
+// MessageOption sets an optional parameter of the send and edit methods.
+type MessageOption func(params)
+
+// WithParseMode sets how the text is formatted: MarkDownParseMode, MarkdownV2ParseMode or HTMLParseMode.
+func WithParseMode(parseMode string) MessageOption {
+	return func(p params) {
+		p["parse_mode"] = parseMode
+	}
+}
+
+// WithDisableNotification sends the message silently.
+func WithDisableNotification(disable bool) MessageOption {
+	return func(p params) {
+		p["disable_notification"] = disable
+	}
+}
+
+// WithDisableWebPagePreview disables link previews in the message.
+func WithDisableWebPagePreview(disable bool) MessageOption {
+	return func(p params) {
+		p["link_preview_options"] = map[string]bool{"is_disabled": disable}
+	}
+}
+
+// WithReplyTo sends the message as a reply to messageID.
+func WithReplyTo(messageID int64) MessageOption {
+	return func(p params) {
+		p["reply_parameters"] = map[string]int64{"message_id": messageID}
+	}
+}
+
+// WithInlineKeyboard attaches an inline keyboard to the message.
+func WithInlineKeyboard(keyboard *InlineKeyboardMarkup) MessageOption {
+	return func(p params) {
+		p["reply_markup"] = keyboard
+	}
+}
+
+func newParams(chatID string, options []MessageOption) params {
+	p := params{"chat_id": chatID}
+	for _, o := range options {
+		o(p)
+	}
+	return p
+}
+
+// SendMessage sends a text message to chatID, either a numeric id or a @channelusername.
+func (c *Client) SendMessage(ctx context.Context, chatID, text string, options ...MessageOption) (*Message, error) {
+	p := newParams(chatID, options)
+	p["text"] = text
+	return call[*Message](ctx, c, "sendMessage", p)
+}
+
+// SendText sends text to chatID with parseMode, discarding the sent message.
+// It matches notify.TelegramSendFunc.
+func (c *Client) SendText(ctx context.Context, chatID, text, parseMode string) error {
+	_, err := c.SendMessage(ctx, chatID, text, WithParseMode(parseMode))
+	return err
+}
+
+// SendPhoto sends a photo with an optional caption.
+func (c *Client) SendPhoto(ctx context.Context, chatID string, photo InputFile, caption string, options ...MessageOption) (*Message, error) {
+	p := newParams(chatID, options)
+	p["photo"] = photo
+	if caption != "" {
+		p["caption"] = caption
+	}
+	return call[*Message](ctx, c, "sendPhoto", p)
+}
+
+// SendDocument sends a general file with an optional caption.
+func (c *Client) SendDocument(ctx context.Context, chatID string, document InputFile, caption string, options ...MessageOption) (*Message, error) {
+	p := newParams(chatID, options)
+	p["document"] = document
+	if caption != "" {
+		p["caption"] = caption
+	}
+	return call[*Message](ctx, c, "sendDocument", p)
+}
+
+// EditMessageText replaces the text of a message sent by the bot.
+func (c *Client) EditMessageText(ctx context.Context, chatID string, messageID int64, text string, options ...MessageOption) (*Message, error) {
+	p := newParams(chatID, options)
+	p["message_id"] = messageID
+	p["text"] = text
+	return call[*Message](ctx, c, "editMessageText", p)
+}
+
+// EditMessageReplyMarkup replaces the inline keyboard of a message, nil removes it.
+func (c *Client) EditMessageReplyMarkup(ctx context.Context, chatID string, messageID int64, keyboard *InlineKeyboardMarkup) (*Message, error) {
+	p := newParams(chatID, nil)
+	p["message_id"] = messageID
+	if keyboard != nil {
+		p["reply_markup"] = keyboard
+	}
+	return call[*Message](ctx, c, "editMessageReplyMarkup", p)
+}
+
+// DeleteMessage deletes a message sent in chatID.
+func (c *Client) DeleteMessage(ctx context.Context, chatID string, messageID int64) error {
+	p := newParams(chatID, nil)
+	p["message_id"] = messageID
+	_, err := call[bool](ctx, c, "deleteMessage", p)
+	return err
+}
+
+// GetMe returns the bot own user, useful to check the token.
+func (c *Client) GetMe(ctx context.Context) (*User, error) {
+	return call[*User](ctx, c, "getMe", params{})
+}
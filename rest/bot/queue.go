@@ -0,0 +1,315 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrQueueFull   = errors.New("[telegram] send queue is full")
+	ErrQueueClosed = errors.New("[telegram] send queue is shut down")
+)
+
+// QueueSettings configures a Queue, zero values select the defaults.
+type QueueSettings struct {
+	// Capacity is the number of messages waiting to be sent, 1000 by default.
+	Capacity int
+	// GlobalRate is the number of messages sent per second across all chats, 30 by default.
+	GlobalRate int
+	// ChatInterval is the minimum delay between two messages of the same chat, 1s by default.
+	// Telegram allows about one message per second in a chat and 20 per minute in a group.
+	ChatInterval time.Duration
+	// MaxAttempts is the number of times a message is sent before giving up, 5 by default.
+	MaxAttempts int
+	// OnError receives the messages that could not be sent.
+	OnError func(chatID, text string, err error)
+}
+
+// Queue sends messages in the background, in order within each chat, without exceeding
+// the Telegram rate limits. Messages rejected with 429 are sent again once the delay
+// given by Telegram is over, which pauses every chat as the limit applies to the whole bot.
+// The ones failing on network or server errors are retried with a backoff. Other errors
+// are final and reported to OnError.
+type Queue struct {
+	client   *Client
+	settings QueueSettings
+	interval time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wake    chan struct{}
+	done    chan struct{}
+	sending sync.WaitGroup
+
+	mu         sync.Mutex
+	chats      map[string]*chatQueue
+	order      []string
+	pending    int
+	inFlight   int
+	globalNext time.Time
+	closed     bool
+}
+
+type chatQueue struct {
+	jobs      []*queuedMessage
+	busy      bool
+	notBefore time.Time
+}
+
+type queuedMessage struct {
+	chatID   string
+	text     string
+	options  []MessageOption
+	attempts int
+}
+
+// NewQueue creates a Queue sending with client and starts it. Call Shutdown to stop it.
+func NewQueue(client *Client, settings QueueSettings) *Queue {
+	if settings.Capacity <= 0 {
+		settings.Capacity = 1000
+	}
+	if settings.GlobalRate <= 0 {
+		settings.GlobalRate = 30
+	}
+	if settings.ChatInterval <= 0 {
+		settings.ChatInterval = time.Second
+	}
+	if settings.MaxAttempts <= 0 {
+		settings.MaxAttempts = 5
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		client:   client,
+		settings: settings,
+		interval: time.Second / time.Duration(settings.GlobalRate),
+		ctx:      ctx,
+		cancel:   cancel,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		chats:    make(map[string]*chatQueue),
+	}
+	go q.run()
+	return q
+}
+
+// Enqueue adds a text message for chatID to the queue without waiting for it to be sent.
+// It fails with ErrQueueFull when Capacity messages are already waiting.
+func (q *Queue) Enqueue(chatID, text string, options ...MessageOption) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if q.pending >= q.settings.Capacity {
+		return ErrQueueFull
+	}
+
+	chat, ok := q.chats[chatID]
+	if !ok {
+		chat = &chatQueue{}
+		q.chats[chatID] = chat
+		q.order = append(q.order, chatID)
+	}
+	chat.jobs = append(chat.jobs, &queuedMessage{chatID: chatID, text: text, options: options})
+	q.pending++
+	q.signal()
+	return nil
+}
+
+// Len returns the number of messages waiting to be sent.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending
+}
+
+// Shutdown stops accepting messages and waits for the pending ones to be sent.
+// When ctx is done first, the sends in progress are aborted, the messages still waiting
+// are dropped, reported to OnError with ErrQueueClosed, and ctx.Err() is returned.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.signal()
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-q.done
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) stop() {
+	q.cancel()
+	q.sending.Wait()
+
+	q.mu.Lock()
+	var dropped []*queuedMessage
+	for _, chatID := range q.order {
+		dropped = append(dropped, q.chats[chatID].jobs...)
+	}
+	q.chats, q.order, q.pending = make(map[string]*chatQueue), nil, 0
+	q.mu.Unlock()
+
+	if q.settings.OnError != nil {
+		for _, msg := range dropped {
+			q.settings.OnError(msg.chatID, msg.text, ErrQueueClosed)
+		}
+	}
+	close(q.done)
+}
+
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) run() {
+	defer q.stop()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		q.mu.Lock()
+		if q.closed && q.pending == 0 && q.inFlight == 0 {
+			q.mu.Unlock()
+			return
+		}
+		wait := q.dispatch(time.Now())
+		q.mu.Unlock()
+
+		if wait == 0 {
+			continue
+		}
+		if wait > 0 {
+			timer.Reset(wait)
+		}
+		select {
+		case <-q.wake:
+		case <-timer.C:
+		case <-q.ctx.Done():
+			return
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+	}
+}
+
+// dispatch starts sending the next message whose chat is ready, taking the chats in turns.
+// It returns 0 when a message was started, otherwise how long to wait for one to be ready,
+// or a negative duration when only an Enqueue or the end of a send can make one ready.
+func (q *Queue) dispatch(now time.Time) time.Duration {
+	if now.Before(q.globalNext) {
+		return q.globalNext.Sub(now)
+	}
+
+	wait := time.Duration(-1)
+	next := -1
+	order := q.order[:0]
+	for _, chatID := range q.order {
+		chat := q.chats[chatID]
+		if chat.busy {
+			order = append(order, chatID)
+			continue
+		}
+		if len(chat.jobs) == 0 {
+			// chats are kept until their interval is over so a new message waits for it
+			if now.Before(chat.notBefore) {
+				order = append(order, chatID)
+			} else {
+				delete(q.chats, chatID)
+			}
+			continue
+		}
+
+		order = append(order, chatID)
+		if !now.Before(chat.notBefore) {
+			if next < 0 {
+				next = len(order) - 1
+			}
+		} else if until := chat.notBefore.Sub(now); wait < 0 || until < wait {
+			wait = until
+		}
+	}
+	q.order = order
+	if next < 0 {
+		return wait
+	}
+
+	chatID := q.order[next]
+	chat := q.chats[chatID]
+	msg := chat.jobs[0]
+	chat.jobs = chat.jobs[1:]
+	chat.busy = true
+	q.pending--
+	q.inFlight++
+	q.sending.Add(1)
+	q.globalNext = now.Add(q.interval)
+	// move the chat to the back so the others get their turn
+	q.order = append(append(q.order[:next:next], q.order[next+1:]...), chatID)
+
+	go q.send(chat, msg)
+	return 0
+}
+
+func (q *Queue) send(chat *chatQueue, msg *queuedMessage) {
+	defer q.sending.Done()
+	msg.attempts++
+	_, err := q.client.SendMessage(q.ctx, msg.chatID, msg.text, msg.options...)
+	now := time.Now()
+
+	q.mu.Lock()
+	q.inFlight--
+	chat.busy = false
+	chat.notBefore = now.Add(q.settings.ChatInterval)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		// the flood wait applies to the whole bot, not only to this chat
+		if until := now.Add(apiErr.RetryAfter); until.After(q.globalNext) {
+			q.globalNext = until
+		}
+	}
+	if err != nil && q.ctx.Err() == nil && msg.attempts < q.settings.MaxAttempts {
+		if wait, retry := retryDelay(err, msg.attempts); retry {
+			chat.jobs = append([]*queuedMessage{msg}, chat.jobs...)
+			chat.notBefore = now.Add(max(wait, q.settings.ChatInterval))
+			q.pending++
+			err = nil
+		}
+	}
+	q.signal()
+	q.mu.Unlock()
+
+	if err != nil && q.settings.OnError != nil {
+		q.settings.OnError(msg.chatID, msg.text, err)
+	}
+}
+
+// retryDelay tells whether a failed message is sent again and after how long:
+// the delay asked by Telegram on 429, an exponential backoff on network and server errors.
+func retryDelay(err error, attempts int) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.RetryAfter > 0 {
+			return apiErr.RetryAfter, true
+		}
+		if apiErr.Code != http.StatusTooManyRequests && apiErr.Code < http.StatusInternalServerError {
+			return 0, false
+		}
+	}
+	return min(time.Second<<(attempts-1), 30*time.Second), true
+}
@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		delivered []string
+		limited   atomic.Bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		if body["text"] == "a2" && limited.CompareAndSwap(false, true) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":1}}`))
+			return
+		}
+		mu.Lock()
+		delivered = append(delivered, body["text"].(string))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"chat":{"id":1}}}`))
+	}))
+	defer server.Close()
+
+	queue := NewQueue(NewClient("token", WithBaseURL(server.URL)), QueueSettings{
+		GlobalRate:   100,
+		ChatInterval: 10 * time.Millisecond,
+		OnError: func(chatID, text string, err error) {
+			t.Errorf("message %s not sent: %v", text, err)
+		},
+	})
+	for _, text := range []string{"a1", "a2", "a3"} {
+		require.NoError(t, queue.Enqueue("a", text))
+	}
+	require.NoError(t, queue.Enqueue("b", "b1"))
+
+	start := time.Now()
+	require.NoError(t, queue.Shutdown(context.Background()))
+	require.GreaterOrEqual(t, time.Since(start), time.Second)
+	require.ErrorIs(t, queue.Enqueue("a", "late"), ErrQueueClosed)
+
+	// the retried message keeps its place within its chat
+	require.Equal(t, []string{"a1", "b1", "a2", "a3"}, delivered)
+}
+
+func TestQueue_shutdownTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"chat":{"id":1}}}`))
+	}))
+	defer server.Close()
+
+	var dropped atomic.Int32
+	queue := NewQueue(NewClient("token", WithBaseURL(server.URL)), QueueSettings{
+		Capacity:     3,
+		ChatInterval: time.Hour,
+		OnError: func(chatID, text string, err error) {
+			require.ErrorIs(t, err, ErrQueueClosed)
+			dropped.Add(1)
+		},
+	})
+	// the first message may already be sent, freeing its place
+	accepted := 0
+	for i := 0; i < 5; i++ {
+		if err := queue.Enqueue("a", "msg"); err != nil {
+			require.ErrorIs(t, err, ErrQueueFull)
+			continue
+		}
+		accepted++
+	}
+	require.Less(t, accepted, 5)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, queue.Shutdown(ctx), context.DeadlineExceeded)
+	require.Equal(t, int32(accepted-1), dropped.Load())
+}
+
+func TestQueue_floodWait(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		delivered  = make(map[string]time.Time)
+		limited    = make(chan time.Time, 1)
+		wasLimited bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		mu.Lock()
+		defer mu.Unlock()
+		if body["text"] == "a1" && !wasLimited {
+			wasLimited = true
+			limited <- time.Now()
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests","parameters":{"retry_after":1}}`))
+			return
+		}
+		delivered[body["text"].(string)] = time.Now()
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"chat":{"id":1}}}`))
+	}))
+	defer server.Close()
+
+	queue := NewQueue(NewClient("token", WithBaseURL(server.URL)), QueueSettings{GlobalRate: 100, ChatInterval: 10 * time.Millisecond})
+	require.NoError(t, queue.Enqueue("a", "a1"))
+	limitedAt := <-limited
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, queue.Enqueue("b", "b1"))
+	require.NoError(t, queue.Shutdown(context.Background()))
+
+	// the other chats wait for the flood wait too
+	require.GreaterOrEqual(t, delivered["b1"].Sub(limitedAt), 900*time.Millisecond)
+	require.False(t, delivered["a1"].IsZero())
+}
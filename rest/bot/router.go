@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"strings"
+)
+
+// CommandHandler handles a command message like "/refund tx1", args being ["tx1"].
+type CommandHandler func(ctx context.Context, msg *Message, args []string) error
+
+// CallbackHandler handles the press of an inline keyboard callback button.
+type CallbackHandler func(ctx context.Context, query *CallbackQuery) error
+
+// UpdateHandler handles any update.
+type UpdateHandler func(ctx context.Context, update *Update) error
+
+// Router dispatches updates to the handlers registered for their command or callback data.
+// Register the handlers before receiving updates, dispatching is then safe for concurrent use.
+type Router struct {
+	username  string
+	commands  map[string]CommandHandler
+	callbacks []callbackRoute
+	fallback  UpdateHandler
+	onError   func(update *Update, err error)
+}
+
+type callbackRoute struct {
+	prefix  string
+	handler CallbackHandler
+}
+
+func NewRouter() *Router {
+	return &Router{commands: make(map[string]CommandHandler)}
+}
+
+// Username sets the username of the bot, the one returned by GetMe. Commands addressed
+// to a bot with /name@botname are only routed when botname is this one, which matters in
+// groups shared with other bots. Without a username, addressed commands are never routed.
+func (r *Router) Username(username string) *Router {
+	r.username = strings.TrimPrefix(username, "@")
+	return r
+}
+
+// Command registers handler for /name, name being given without the slash.
+// Commands addressed to this bot with /name@botname are routed as well, see Username.
+func (r *Router) Command(name string, handler CommandHandler) *Router {
+	r.commands[strings.ToLower(strings.TrimPrefix(name, "/"))] = handler
+	return r
+}
+
+// Callback registers handler for the callback queries whose data starts with prefix,
+// the longest matching prefix wins.
+func (r *Router) Callback(prefix string, handler CallbackHandler) *Router {
+	r.callbacks = append(r.callbacks, callbackRoute{prefix: prefix, handler: handler})
+	return r
+}
+
+// Default registers the handler of updates matching no command nor callback.
+func (r *Router) Default(handler UpdateHandler) *Router {
+	r.fallback = handler
+	return r
+}
+
+// OnError registers the function receiving the errors returned by handlers.
+func (r *Router) OnError(fn func(update *Update, err error)) *Router {
+	r.onError = fn
+	return r
+}
+
+// Dispatch routes update to its handler and returns the handler error.
+func (r *Router) Dispatch(ctx context.Context, update *Update) error {
+	err := r.dispatch(ctx, update)
+	if err != nil && r.onError != nil {
+		r.onError(update, err)
+	}
+	return err
+}
+
+func (r *Router) dispatch(ctx context.Context, update *Update) error {
+	if msg := update.Message; msg != nil {
+		if name, botname, args, ok := parseCommand(msg.Text); ok && r.addressed(botname) {
+			if handler, found := r.commands[name]; found {
+				return handler(ctx, msg, args)
+			}
+		}
+	}
+
+	if query := update.CallbackQuery; query != nil {
+		var match *callbackRoute
+		for i, route := range r.callbacks {
+			if strings.HasPrefix(query.Data, route.prefix) && (match == nil || len(route.prefix) > len(match.prefix)) {
+				match = &r.callbacks[i]
+			}
+		}
+		if match != nil {
+			return match.handler(ctx, query)
+		}
+	}
+
+	if r.fallback != nil {
+		return r.fallback(ctx, update)
+	}
+	return nil
+}
+
+// addressed tells whether a command sent to botname, empty when not given, is for this bot.
+func (r *Router) addressed(botname string) bool {
+	return botname == "" || (r.username != "" && strings.EqualFold(botname, r.username))
+}
+
+func parseCommand(text string) (name, botname string, args []string, ok bool) {
+	if !strings.HasPrefix(text, "/") {
+		return "", "", nil, false
+	}
+
+	fields := strings.Fields(text)
+	name, botname, _ = strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+	return strings.ToLower(name), botname, fields[1:], name != ""
+}
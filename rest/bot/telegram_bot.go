@@ -2,31 +2,43 @@ package bot
 
 import (
 	"context"
-	"fmt"
-	"net/http"
 	"os"
-
-	"github.com/alabuta-source/toolkit/rest"
+	"sync"
 )
 
 const (
-	sendMessageURL    = "https://api.telegram.org/bot%s/sendMessage?chat_id=%s&text=%s&parse_mode=%s&disable_notification=%t"
-	MarkDownParseMode = "Markdown"
-	HTMLParseMode     = "HTML"
+	MarkDownParseMode   = "Markdown"
+	MarkdownV2ParseMode = "MarkdownV2"
+	HTMLParseMode       = "HTML"
 )
 
+var defaultClient struct {
+	mu     sync.Mutex
+	client *Client
+}
+
+// envClient returns the Client of the BOT_TOKEN bot, built on first use and
+// rebuilt only when the variable changes.
+func envClient() *Client {
+	token := os.Getenv("BOT_TOKEN")
+
+	defaultClient.mu.Lock()
+	defer defaultClient.mu.Unlock()
+	if defaultClient.client == nil || defaultClient.client.token != token {
+		defaultClient.client = NewClient(token)
+	}
+	return defaultClient.client
+}
+
+// SendTelegramMessage sends message to chatID with the bot whose token is in the
+// BOT_TOKEN environment variable. Use a Client for anything beyond simple text messages.
 func SendTelegramMessage(chatID, message, parseMode string) error {
-	url := fmt.Sprintf(
-		sendMessageURL,
-		os.Getenv("BOT_TOKEN"),
+	_, err := envClient().SendMessage(
+		context.Background(),
 		chatID,
 		message,
-		parseMode,
-		false,
+		WithParseMode(parseMode),
+		WithDisableNotification(false),
 	)
-
-	return rest.
-		NewHttpClient().
-		BuildRequest(url, http.MethodPost).
-		Execute(context.Background())
+	return err
 }
@@ -0,0 +1,81 @@
+package bot
+
+// User is a Telegram user or bot.
+type User struct {
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+// Chat is a private chat, group, supergroup or channel.
+type Chat struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Title    string `json:"title,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// Message is a message sent or received by the bot.
+type Message struct {
+	MessageID   int64                 `json:"message_id"`
+	From        *User                 `json:"from,omitempty"`
+	Chat        Chat                  `json:"chat"`
+	Date        int64                 `json:"date"`
+	EditDate    int64                 `json:"edit_date,omitempty"`
+	Text        string                `json:"text,omitempty"`
+	Caption     string                `json:"caption,omitempty"`
+	Photo       []PhotoSize           `json:"photo,omitempty"`
+	Document    *Document             `json:"document,omitempty"`
+	ReplyTo     *Message              `json:"reply_to_message,omitempty"`
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+type PhotoSize struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+type Document struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+// InlineKeyboardMarkup is a keyboard shown right below a message.
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// InlineKeyboardButton must set exactly one of URL or CallbackData.
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	URL          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
+}
+
+// NewInlineKeyboard builds a keyboard with one row per argument.
+func NewInlineKeyboard(rows ...[]InlineKeyboardButton) *InlineKeyboardMarkup {
+	return &InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// NewInlineKeyboardRow groups buttons on the same row.
+func NewInlineKeyboardRow(buttons ...InlineKeyboardButton) []InlineKeyboardButton {
+	return buttons
+}
+
+// CallbackButton sends data back to the bot when pressed.
+func CallbackButton(text, data string) InlineKeyboardButton {
+	return InlineKeyboardButton{Text: text, CallbackData: data}
+}
+
+// URLButton opens url when pressed.
+func URLButton(text, url string) InlineKeyboardButton {
+	return InlineKeyboardButton{Text: text, URL: url}
+}
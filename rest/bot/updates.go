@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/alabuta-source/toolkit/rest"
+)
+
+// Update is an incoming update. At most one of its optional fields is set.
+type Update struct {
+	UpdateID          int64          `json:"update_id"`
+	Message           *Message       `json:"message,omitempty"`
+	EditedMessage     *Message       `json:"edited_message,omitempty"`
+	ChannelPost       *Message       `json:"channel_post,omitempty"`
+	EditedChannelPost *Message       `json:"edited_channel_post,omitempty"`
+	CallbackQuery     *CallbackQuery `json:"callback_query,omitempty"`
+}
+
+// CallbackQuery is sent when a user presses a callback button of an inline keyboard.
+type CallbackQuery struct {
+	ID           string   `json:"id"`
+	From         User     `json:"from"`
+	Message      *Message `json:"message,omitempty"`
+	ChatInstance string   `json:"chat_instance"`
+	Data         string   `json:"data,omitempty"`
+}
+
+// GetUpdates long polls for updates with an id of at least offset, waiting up to
+// timeout for one to arrive.
+func (c *Client) GetUpdates(ctx context.Context, offset int64, limit int, timeout time.Duration, allowedUpdates ...string) ([]Update, error) {
+	p := params{
+		"offset":  offset,
+		"timeout": int64(timeout.Seconds()),
+	}
+	if limit > 0 {
+		p["limit"] = int64(limit)
+	}
+	if len(allowedUpdates) > 0 {
+		p["allowed_updates"] = allowedUpdates
+	}
+	// the request must outlive the long poll
+	return call[[]Update](ctx, c, "getUpdates", p, rest.RequestWithTimeout(timeout+10*time.Second))
+}
+
+// SetWebhook makes Telegram push updates to url, sending secretToken in the
+// X-Telegram-Bot-Api-Secret-Token header of every call when not empty.
+func (c *Client) SetWebhook(ctx context.Context, url, secretToken string, allowedUpdates ...string) error {
+	p := params{"url": url}
+	if secretToken != "" {
+		p["secret_token"] = secretToken
+	}
+	if len(allowedUpdates) > 0 {
+		p["allowed_updates"] = allowedUpdates
+	}
+	_, err := call[bool](ctx, c, "setWebhook", p)
+	return err
+}
+
+// DeleteWebhook switches the bot back to getUpdates.
+func (c *Client) DeleteWebhook(ctx context.Context, dropPendingUpdates bool) error {
+	_, err := call[bool](ctx, c, "deleteWebhook", params{"drop_pending_updates": dropPendingUpdates})
+	return err
+}
+
+// AnswerCallbackQuery acknowledges a callback query, optionally showing text to the user.
+func (c *Client) AnswerCallbackQuery(ctx context.Context, callbackQueryID, text string, showAlert bool) error {
+	p := params{"callback_query_id": callbackQueryID}
+	if text != "" {
+		p["text"] = text
+		p["show_alert"] = showAlert
+	}
+	_, err := call[bool](ctx, c, "answerCallbackQuery", p)
+	return err
+}
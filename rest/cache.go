@@ -0,0 +1,299 @@
+package rest
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheHeader is set to "HIT" on responses served from the cache,
+// including the ones revalidated with the server.
+const CacheHeader = "X-Toolkit-Cache"
+
+// CachedResponse is a response kept by a CacheStore.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Expires is when the response stops being fresh and must be revalidated.
+	Expires time.Time
+	// Vary holds the request headers named by the Vary response header.
+	Vary map[string]string
+}
+
+func (c *CachedResponse) fresh() bool {
+	return time.Now().Before(c.Expires)
+}
+
+func (c *CachedResponse) matches(req *http.Request) bool {
+	for name, value := range c.Vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *CachedResponse) response(req *http.Request) *http.Response {
+	header := c.Header.Clone()
+	header.Set(CacheHeader, "HIT")
+	return &http.Response{
+		Status:        strconv.Itoa(c.StatusCode) + " " + http.StatusText(c.StatusCode),
+		StatusCode:    c.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// CacheStore is the storage backend of the response cache, it must be safe for concurrent use.
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse)
+	Delete(key string)
+}
+
+// WithCache caches GET responses in store following the HTTP caching rules:
+// fresh responses (Cache-Control max-age, Expires) are served without reaching the server,
+// stale ones carrying an ETag or Last-Modified are revalidated with a conditional request.
+// Responses marked no-store are never kept, requests sent with Cache-Control no-store
+// bypass the cache and no-cache forces a revalidation.
+//
+// Entries are kept per Authorization value, hashed, and per value of the keyHeaders,
+// like a tenant header, so a shared client never serves the response of one caller to
+// another. Register the cache after the middlewares setting those headers, such as
+// WithTokenSource. A write only invalidates the entry of its own caller.
+func WithCache(store CacheStore, keyHeaders ...string) ClientOption {
+	return WithMiddleware(CacheMiddleware(store, keyHeaders...))
+}
+
+func CacheMiddleware(store CacheStore, keyHeaders ...string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			key := cacheKey(req, keyHeaders)
+			if req.Method != http.MethodGet {
+				resp, err := next.Do(req)
+				if err == nil && req.Method != http.MethodHead && resp.StatusCode < http.StatusBadRequest {
+					store.Delete(key)
+				}
+				return resp, err
+			}
+
+			reqDirectives := parseCacheControl(req.Header.Get("Cache-Control"))
+			if _, ok := reqDirectives["no-store"]; ok {
+				return next.Do(req)
+			}
+
+			cached, ok := store.Get(key)
+			if ok && !cached.matches(req) {
+				cached, ok = nil, false
+			}
+			if ok {
+				if _, noCache := reqDirectives["no-cache"]; !noCache && cached.fresh() {
+					return cached.response(req), nil
+				}
+				setValidators(req, cached)
+			}
+
+			resp, err := next.Do(req)
+			if err != nil {
+				return resp, err
+			}
+
+			if ok && resp.StatusCode == http.StatusNotModified {
+				_, _ = io.Copy(io.Discard, resp.Body)
+				_ = resp.Body.Close()
+
+				updated := *cached
+				updated.Header = cached.Header.Clone()
+				for name, values := range resp.Header {
+					updated.Header[name] = values
+				}
+				updated.Expires = expiresAt(updated.Header)
+				store.Set(key, &updated)
+				return updated.response(req), nil
+			}
+
+			return storeResponse(store, key, req, resp)
+		})
+	}
+}
+
+// cacheKey identifies the entry of req: its url, the hash of its credentials and
+// the values of the keyHeaders.
+func cacheKey(req *http.Request, keyHeaders []string) string {
+	key := req.URL.String()
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		key += "\x00auth=" + hex.EncodeToString(sum[:])
+	}
+	for _, name := range keyHeaders {
+		key += "\x00" + strings.ToLower(name) + "=" + req.Header.Get(name)
+	}
+	return key
+}
+
+func setValidators(req *http.Request, cached *CachedResponse) {
+	if etag := cached.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if modified := cached.Header.Get("Last-Modified"); modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+}
+
+// storeResponse keeps resp in the store when it is cacheable, handing back an equivalent response.
+func storeResponse(store CacheStore, key string, req *http.Request, resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	directives := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if _, noStore := directives["no-store"]; noStore || strings.TrimSpace(resp.Header.Get("Vary")) == "*" {
+		return resp, nil
+	}
+
+	expires := expiresAt(resp.Header)
+	hasValidator := resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+	if !expires.After(time.Now()) && !hasValidator {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	cached := &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		Expires:    expires,
+	}
+	for _, name := range strings.Split(resp.Header.Get("Vary"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			if cached.Vary == nil {
+				cached.Vary = make(map[string]string)
+			}
+			cached.Vary[name] = req.Header.Get(name)
+		}
+	}
+	store.Set(key, cached)
+	return resp, nil
+}
+
+// expiresAt computes until when a response is fresh from its Cache-Control,
+// Age and Expires headers. A zero time means it must always be revalidated.
+func expiresAt(header http.Header) time.Time {
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	if _, noCache := directives["no-cache"]; noCache {
+		return time.Time{}
+	}
+
+	if maxAge, ok := directives["max-age"]; ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil {
+			return time.Time{}
+		}
+		age, _ := strconv.Atoi(header.Get("Age"))
+		return time.Now().Add(time.Duration(seconds-age) * time.Second)
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		if date, err := http.ParseTime(expires); err == nil {
+			return date
+		}
+	}
+	return time.Time{}
+}
+
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+	}
+	return directives
+}
+
+// NewLRUCache creates an in memory CacheStore keeping at most capacity responses,
+// evicting the least recently used ones first.
+func NewLRUCache(capacity int) CacheStore {
+	if capacity <= 0 {
+		capacity = 100
+	}
+	return &lruCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type lruEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+func (c *lruCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruEntry).response, true
+}
+
+func (c *lruCache) Set(key string, response *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruEntry).response = response
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, response: response})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCache(t *testing.T) {
+	var calls, revalidations int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				revalidations++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		_, _ = w.Write([]byte(`{"id":7}`))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient(WithBaseURL(server.URL), WithCache(NewLRUCache(10)))
+	for _, path := range []string{"/fresh", "/fresh", "/etag", "/etag", "/etag"} {
+		resp, err := Do[user](context.Background(), client, NewRequest(path, http.MethodGet))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, 7, resp.Data.ID)
+	}
+	require.Equal(t, 4, calls)
+	require.Equal(t, 2, revalidations)
+
+	req := NewRequest("/fresh", http.MethodGet, RequestWithHeaders(map[string]string{"Cache-Control": "no-store"}))
+	require.NoError(t, client.ExecuteRequest(context.Background(), req))
+	require.Equal(t, 5, calls)
+}
+
+func TestWithCache_perCaller(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(`{"name":"` + r.Header.Get("Authorization") + "/" + r.Header.Get("X-Tenant") + `"}`))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient(WithBaseURL(server.URL), WithCache(NewLRUCache(10), "X-Tenant"))
+	get := func(auth, tenant string) string {
+		resp, err := Do[user](context.Background(), client, NewRequest("/me", http.MethodGet,
+			RequestWithHeaders(map[string]string{"Authorization": auth, "X-Tenant": tenant})))
+		require.NoError(t, err)
+		return resp.Data.Name
+	}
+
+	require.Equal(t, "alice/t1", get("alice", "t1"))
+	require.Equal(t, "bob/t1", get("bob", "t1"))
+	require.Equal(t, "alice/t2", get("alice", "t2"))
+	require.Equal(t, "alice/t1", get("alice", "t1"))
+}
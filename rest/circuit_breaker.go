@@ -0,0 +1,244 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, without reaching the server, while the circuit of a host is open.
+var ErrCircuitOpen = errors.New("[restClient] circuit breaker is open")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerSettings configures the circuit breaker kept for each host.
+// Zero values fall back to the defaults noted on each field.
+type CircuitBreakerSettings struct {
+	// WindowSize is the number of most recent outcomes the failure rate is computed on, 20 by default.
+	WindowSize int
+	// MinRequests is the number of outcomes needed before the circuit can open, 10 by default.
+	MinRequests int
+	// FailureRate, between 0 and 1, opens the circuit once reached, 0.5 by default.
+	FailureRate float64
+	// Cooldown is how long the circuit stays open before letting probes through, 30s by default.
+	Cooldown time.Duration
+	// HalfOpenRequests is the number of successful probes needed to close the circuit, 1 by default.
+	HalfOpenRequests int
+	// IsFailure tells whether an outcome counts as a failure,
+	// transport errors and 5xx responses by default. Requests cancelled by the caller
+	// say nothing about the host and are never recorded.
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange is called every time the circuit of a host changes state.
+	OnStateChange func(host string, from, to CircuitState)
+}
+
+func (s CircuitBreakerSettings) withDefaults() CircuitBreakerSettings {
+	if s.WindowSize <= 0 {
+		s.WindowSize = 20
+	}
+	if s.MinRequests <= 0 {
+		s.MinRequests = 10
+	}
+	if s.MinRequests > s.WindowSize {
+		s.MinRequests = s.WindowSize
+	}
+	if s.FailureRate <= 0 {
+		s.FailureRate = 0.5
+	}
+	if s.Cooldown <= 0 {
+		s.Cooldown = 30 * time.Second
+	}
+	if s.HalfOpenRequests <= 0 {
+		s.HalfOpenRequests = 1
+	}
+	if s.IsFailure == nil {
+		s.IsFailure = func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= http.StatusInternalServerError
+		}
+	}
+	return s
+}
+
+// WithCircuitBreaker protects every host the client talks to with its own circuit breaker.
+func WithCircuitBreaker(settings CircuitBreakerSettings) ClientOption {
+	return WithMiddleware(CircuitBreakerMiddleware(settings))
+}
+
+// CircuitBreakerMiddleware fails fast with ErrCircuitOpen while a host keeps failing.
+// A circuit opens when the failure rate over the window reaches FailureRate, stays open
+// for Cooldown and then lets HalfOpenRequests probes through to decide whether to close again.
+func CircuitBreakerMiddleware(settings CircuitBreakerSettings) Middleware {
+	settings = settings.withDefaults()
+
+	var mu sync.Mutex
+	breakers := make(map[string]*circuitBreaker)
+	breakerFor := func(host string) *circuitBreaker {
+		mu.Lock()
+		defer mu.Unlock()
+
+		cb, ok := breakers[host]
+		if !ok {
+			cb = &circuitBreaker{host: host, settings: settings, outcomes: make([]bool, settings.WindowSize)}
+			breakers[host] = cb
+		}
+		return cb
+	}
+
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			cb := breakerFor(req.URL.Host)
+			generation, ok := cb.allow()
+			if !ok {
+				return nil, ErrCircuitOpen
+			}
+
+			resp, err := next.Do(req)
+			if errors.Is(err, context.Canceled) {
+				cb.abandon(generation)
+				return resp, err
+			}
+			cb.record(generation, settings.IsFailure(resp, err))
+			return resp, err
+		})
+	}
+}
+
+type circuitBreaker struct {
+	mu       sync.Mutex
+	host     string
+	settings CircuitBreakerSettings
+
+	state    CircuitState
+	openedAt time.Time
+	// generation changes with every state change, outcomes of requests let
+	// through under an older generation are ignored.
+	generation uint64
+
+	outcomes []bool
+	next     int
+	count    int
+	failures int
+
+	probes    int
+	successes int
+}
+
+func (cb *circuitBreaker) allow() (uint64, bool) {
+	cb.mu.Lock()
+	var from CircuitState
+	changed := false
+	defer func() {
+		cb.mu.Unlock()
+		if changed {
+			cb.notify(from, CircuitHalfOpen)
+		}
+	}()
+
+	if cb.state == CircuitOpen {
+		if time.Since(cb.openedAt) < cb.settings.Cooldown {
+			return 0, false
+		}
+		from, changed = cb.state, true
+		cb.setState(CircuitHalfOpen)
+	}
+
+	if cb.state == CircuitHalfOpen {
+		if cb.probes >= cb.settings.HalfOpenRequests {
+			return 0, false
+		}
+		cb.probes++
+	}
+	return cb.generation, true
+}
+
+// abandon releases the probe slot of a request whose outcome is not recorded.
+func (cb *circuitBreaker) abandon(generation uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if generation == cb.generation && cb.state == CircuitHalfOpen {
+		cb.probes--
+	}
+}
+
+func (cb *circuitBreaker) record(generation uint64, failure bool) {
+	cb.mu.Lock()
+	if generation != cb.generation {
+		cb.mu.Unlock()
+		return
+	}
+
+	from := cb.state
+	switch cb.state {
+	case CircuitClosed:
+		cb.push(failure)
+		if cb.count >= cb.settings.MinRequests &&
+			float64(cb.failures)/float64(cb.count) >= cb.settings.FailureRate {
+			cb.setState(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		cb.probes--
+		if failure {
+			cb.setState(CircuitOpen)
+		} else if cb.successes++; cb.successes >= cb.settings.HalfOpenRequests {
+			cb.setState(CircuitClosed)
+		}
+	}
+	to := cb.state
+	cb.mu.Unlock()
+
+	if from != to {
+		cb.notify(from, to)
+	}
+}
+
+func (cb *circuitBreaker) push(failure bool) {
+	if cb.count == len(cb.outcomes) {
+		if cb.outcomes[cb.next] {
+			cb.failures--
+		}
+	} else {
+		cb.count++
+	}
+	cb.outcomes[cb.next] = failure
+	if failure {
+		cb.failures++
+	}
+	cb.next = (cb.next + 1) % len(cb.outcomes)
+}
+
+// setState moves to state and resets the counters, callers must hold the lock.
+func (cb *circuitBreaker) setState(state CircuitState) {
+	cb.state = state
+	cb.generation++
+	cb.next, cb.count, cb.failures = 0, 0, 0
+	cb.probes, cb.successes = 0, 0
+	if state == CircuitOpen {
+		cb.openedAt = time.Now()
+	}
+}
+
+func (cb *circuitBreaker) notify(from, to CircuitState) {
+	if cb.settings.OnStateChange != nil {
+		cb.settings.OnStateChange(cb.host, from, to)
+	}
+}
@@ -1,109 +1,317 @@
 package rest
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
 const defaultTimeout = 5 * time.Second
 
+// RestClient sends requests built with NewRequest.
+// It is configured once through ClientOption values and is safe for concurrent use,
+// every request shares the same http.Client so connections are kept alive and reused.
 type RestClient struct {
-	httpClient     *http.Client
-	requestOptions *clientReQuestOptions
-	url            string
-	method         string
-}
+	httpClient  *http.Client
+	transport   http.RoundTripper
+	wrappers    []func(http.RoundTripper) http.RoundTripper
+	baseURL     string
+	headers     map[string]string
+	timeout     time.Duration
+	retryPolicy RetryPolicy
+	middlewares []Middleware
+
+	tlsConfig       *tls.Config
+	tlsOptions      []func(*tls.Config)
+	certificatePins []string
 
-func NewHttpClient() *RestClient {
-	return &RestClient{}
+	// request is only used by the deprecated BuildRequest/Execute pair.
+	request *Request
 }
 
-func (client *RestClient) BuildRequest(url, method string, options ...Option) *RestClient {
-	var requestOptions clientReQuestOptions
+func NewHttpClient(options ...ClientOption) *RestClient {
+	client := &RestClient{timeout: defaultTimeout}
 	for _, o := range options {
-		o.Apply(&requestOptions)
+		o.Apply(client)
 	}
 
-	var timeout = defaultTimeout
-	if requestOptions.timeout.String() != "0s" {
-		timeout = requestOptions.timeout
+	if client.transport == nil {
+		client.transport = newDefaultTransport()
 	}
-
-	checkRedirectFunc := requestOptions.checkRedirectFunc
-	if checkRedirectFunc == nil {
-		checkRedirectFunc = defaultCheckRedirect
+	client.applyTLS()
+	for _, wrap := range client.wrappers {
+		client.transport = wrap(client.transport)
 	}
+	client.httpClient = &http.Client{Transport: client.transport, CheckRedirect: defaultCheckRedirect}
+	return client
+}
 
-	client.httpClient = &http.Client{Timeout: timeout, CheckRedirect: checkRedirectFunc}
-	client.requestOptions = &requestOptions
-	client.method = method
-	client.url = url
+func newDefaultTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 20
+	return transport
+}
 
+// BuildRequest stores the request on the client to be sent by Execute.
+//
+// Deprecated: the stored request makes the client unsafe to share between goroutines,
+// use NewRequest with ExecuteRequest or Do instead.
+func (client *RestClient) BuildRequest(url, method string, options ...Option) *RestClient {
+	client.request = NewRequest(url, method, options...)
 	return client
 }
 
+// Execute sends the request stored by BuildRequest.
+//
+// Deprecated: use ExecuteRequest or Do instead.
 func (client *RestClient) Execute(ctx context.Context) error {
-	var buf bytes.Buffer
-	if client.requestOptions.body != nil {
-		if err := json.NewEncoder(&buf).Encode(client.requestOptions.body); err != nil {
-			return fmt.Errorf("[restClient] error encode request body: %v", err)
+	if client.request == nil {
+		return errors.New("[restClient] request not built, call BuildRequest before Execute")
+	}
+	return client.ExecuteRequest(ctx, client.request)
+}
+
+// ExecuteRequest sends req and decodes the response into the value given
+// with RequestWithDecodeValue, if any. An empty body, like the one of a 204, leaves it untouched.
+func (client *RestClient) ExecuteRequest(ctx context.Context, req *Request) error {
+	resp, err := client.send(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if req.options.decode != nil && len(resp.body) > 0 {
+		if err = req.decode(resp.header, resp.body, req.options.decode); err != nil {
+			return fmt.Errorf("error trying to Unmarshal response: %v", err)
+		}
+	}
+	return nil
+}
+
+// rawResponse is the undecoded outcome of a request, shared by ExecuteRequest and Do.
+type rawResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+	duration   time.Duration
+}
+
+func (client *RestClient) send(ctx context.Context, req *Request) (*rawResponse, error) {
+	if req.hedged() {
+		return client.sendHedged(ctx, req)
+	}
+	return client.sendOnce(ctx, req)
+}
+
+func (client *RestClient) sendOnce(ctx context.Context, req *Request) (*rawResponse, error) {
+	start := time.Now()
+	request, resp, cancel, err := client.roundTrip(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	raw, err := client.doRequest(req, request, resp)
+	if err != nil {
+		return nil, err
+	}
+	raw.duration = time.Since(start)
+	return raw, nil
+}
+
+// roundTrip sends req, retrying as the retry policy asks, and returns the last response
+// with its body unread. cancel releases the request context and must be called once
+// the body has been consumed.
+func (client *RestClient) roundTrip(
+	ctx context.Context,
+	req *Request,
+	stream bool,
+) (request *http.Request, resp *http.Response, cancel context.CancelFunc, err error) {
+	body, err := req.encodeBody()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	policy := req.options.retryPolicy
+	if policy == nil {
+		policy = client.retryPolicy
+	}
+	if body.stream != nil {
+		// a stream can only be read once
+		policy = nil
+	}
+
+	doer := chain(client.doerFor(req), client.middlewares)
+	for attempt := 1; ; attempt++ {
+		attemptCtx, attemptCancel, headersReceived := client.attemptContext(ctx, req, stream)
+		cancel = attemptCancel
+
+		request, err = client.newHTTPRequest(attemptCtx, req, body)
+		if err != nil {
+			cancel()
+			return nil, nil, nil, err
 		}
+
+		resp, err = doer.Do(request)
+		headersReceived()
+
+		// the attempt context may have timed out, only the caller's context ends the retries
+		if policy != nil && ctx.Err() == nil {
+			if wait, retry := policy.Retry(attempt, request, resp, err); retry {
+				if resp != nil {
+					_, _ = io.Copy(io.Discard, resp.Body)
+					_ = resp.Body.Close()
+				}
+				cancel()
+				if err = sleep(ctx, wait); err != nil {
+					return nil, nil, nil, err
+				}
+				continue
+			}
+		}
+
+		if err != nil {
+			cancel()
+			return nil, nil, nil, fmt.Errorf(
+				"error doing the request, message: %w, "+
+					"url: %s",
+				err, request.URL.Path,
+			)
+		}
+		return request, resp, cancel, nil
+	}
+}
+
+// attemptContext bounds a single attempt with the request timeout. For streams the
+// timeout only runs until headersReceived is called, so long bodies can be read at their own pace.
+func (client *RestClient) attemptContext(
+	ctx context.Context,
+	req *Request,
+	stream bool,
+) (attemptCtx context.Context, cancel context.CancelFunc, headersReceived func()) {
+	timeout := client.timeout
+	if req.options.timeout > 0 {
+		timeout = req.options.timeout
+	}
+
+	switch {
+	case timeout <= 0:
+		attemptCtx, cancel = context.WithCancel(ctx)
+		return attemptCtx, cancel, func() {}
+	case !stream:
+		attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		return attemptCtx, cancel, func() {}
+	}
+
+	attemptCtx, cancel = context.WithCancel(ctx)
+	timer := time.AfterFunc(timeout, cancel)
+	return attemptCtx, cancel, func() { timer.Stop() }
+}
+
+func (client *RestClient) newHTTPRequest(ctx context.Context, req *Request, body requestBody) (*http.Request, error) {
+	target, err := client.requestURL(req)
+	if err != nil {
+		return nil, err
 	}
 
-	request, err := http.NewRequestWithContext(ctx, client.method, client.url, &buf)
+	request, err := http.NewRequestWithContext(ctx, req.method, target, body.reader())
 	if err != nil {
-		return fmt.Errorf(
+		return nil, fmt.Errorf(
 			"error trying to build %s request, message: %v",
-			client.method,
+			req.method,
 			err,
 		)
 	}
 
-	request.Header.Set("content-type", "application/json")
+	request.Header.Set("content-type", body.contentType)
 	request.Header.Add("charset", "utf-8")
 	request.Header.Add("library", "toolkit-alabuta")
-	for k, v := range client.requestOptions.headers {
+	for k, v := range client.headers {
 		request.Header.Set(k, v)
 	}
+	for k, v := range req.options.headers {
+		request.Header.Set(k, v)
+	}
+	return request, nil
+}
+
+// requestURL expands the path params of req, resolves it against the client base url
+// and appends its query parameters.
+func (client *RestClient) requestURL(req *Request) (string, error) {
+	path, err := req.expandPath()
+	if err != nil {
+		return "", err
+	}
+	target := client.resolve(path)
+	if len(req.options.query) == 0 {
+		return target, nil
+	}
 
-	data, er := client.doRequest(request)
-	if er != nil {
-		return er
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("[restClient] invalid url %s: %v", target, err)
 	}
+	query := parsed.Query()
+	for k, v := range req.options.query {
+		query[k] = append(query[k], v...)
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
 
-	if client.requestOptions.decode != nil {
-		if err = json.Unmarshal(data, client.requestOptions.decode); err != nil {
-			return fmt.Errorf("error trying to Unmarshal response: %v", err)
-		}
+// resolve prefixes url with the client base url unless it is already absolute.
+func (client *RestClient) resolve(url string) string {
+	if client.baseURL == "" || strings.Contains(url, "://") {
+		return url
 	}
-	return nil
+	if url == "" {
+		return client.baseURL
+	}
+	return strings.TrimRight(client.baseURL, "/") + "/" + strings.TrimLeft(url, "/")
 }
 
-func (client *RestClient) doRequest(req *http.Request) ([]byte, error) {
-	resp, er := client.httpClient.Do(req)
-	if er != nil {
-		return nil, fmt.Errorf(
-			"error doing the request, message: %s, "+
-				"url: %s",
-			er.Error(), req.URL.Path,
-		)
+// doerFor returns the shared http.Client, or a shallow copy of it sharing the same
+// transport when the request customizes the redirect policy.
+func (client *RestClient) doerFor(req *Request) *http.Client {
+	if req.options.checkRedirectFunc == nil {
+		return client.httpClient
 	}
+	httpClient := *client.httpClient
+	httpClient.CheckRedirect = req.options.checkRedirectFunc
+	return &httpClient
+}
+
+func (client *RestClient) doRequest(r *Request, req *http.Request, resp *http.Response) (*rawResponse, error) {
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+	if !isSuccess(resp.StatusCode) {
 		bytes, err := client.closeBodyAndSendResponse(resp.Body)
 		if err != nil {
 			return nil, fmt.Errorf("error reading the body: %v", err)
 		}
-		return nil, errors.New(string(bytes))
+		return nil, newHTTPError(r, req, resp, bytes)
 	}
-	return client.closeBodyAndSendResponse(resp.Body)
+
+	data, err := client.closeBodyAndSendResponse(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &rawResponse{
+		statusCode: resp.StatusCode,
+		header:     resp.Header,
+		body:       data,
+	}, nil
+}
+
+// isSuccess reports whether status is a 2xx, any other final status being an HTTPError.
+func isSuccess(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
 }
 
 func (client *RestClient) closeBodyAndSendResponse(body io.ReadCloser) ([]byte, error) {
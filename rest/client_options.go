@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+)
+
+type ClientOptionFunc func(*RestClient)
+
+type ClientOption interface {
+	Apply(*RestClient)
+}
+
+func (f ClientOptionFunc) Apply(client *RestClient) {
+	f(client)
+}
+
+// WithMiddleware registers middlewares that wrap every request sent by the client.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return ClientOptionFunc(func(c *RestClient) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	})
+}
+
+// WithBaseURL sets the url relative request urls are resolved against.
+func WithBaseURL(baseURL string) ClientOption {
+	return ClientOptionFunc(func(c *RestClient) {
+		c.baseURL = baseURL
+	})
+}
+
+// WithDefaultHeaders sets headers sent with every request,
+// headers given with RequestWithHeaders take precedence.
+func WithDefaultHeaders(headers map[string]string) ClientOption {
+	return ClientOptionFunc(func(c *RestClient) {
+		c.headers = headers
+	})
+}
+
+// WithTransport replaces the pooled transport created by NewHttpClient.
+// The TLS options only apply to *http.Transport values: use WithTransportWrapper to
+// wrap the transport, e.g. for tracing, and keep them.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return ClientOptionFunc(func(c *RestClient) {
+		c.transport = transport
+	})
+}
+
+// WithTransportWrapper wraps the transport of the client once the TLS options are
+// applied to it. Wrappers are applied in order, the last one being the outermost.
+func WithTransportWrapper(wrap func(http.RoundTripper) http.RoundTripper) ClientOption {
+	return ClientOptionFunc(func(c *RestClient) {
+		c.wrappers = append(c.wrappers, wrap)
+	})
+}
+
+// WithTimeout sets the default timeout of each attempt, 5s if not set.
+// RequestWithTimeout overrides it for a single request, zero disables it.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return ClientOptionFunc(func(c *RestClient) {
+		c.timeout = timeout
+	})
+}
+
+// WithRetryPolicy sets the retry policy used by requests that do not set their own.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return ClientOptionFunc(func(c *RestClient) {
+		c.retryPolicy = policy
+	})
+}
@@ -0,0 +1,370 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set("x-request-id", "abc")
+		_, _ = w.Write([]byte(`{"id":1,"name":"alabuta"}`))
+	}))
+	defer server.Close()
+
+	resp, err := Do[user](context.Background(), NewHttpClient(), NewRequest(server.URL, http.MethodGet))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "abc", resp.Header.Get("x-request-id"))
+	require.Equal(t, user{ID: 1, Name: "alabuta"}, resp.Data)
+	require.JSONEq(t, `{"id":1,"name":"alabuta"}`, string(resp.Body))
+}
+
+func TestExecute_decode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("content-type"))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":2,"name":"toolkit"}`))
+	}))
+	defer server.Close()
+
+	var u user
+	err := NewHttpClient().
+		BuildRequest(server.URL, http.MethodPost, RequestWithBody(u), RequestWithDecodeValue(&u)).
+		Execute(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, user{ID: 2, Name: "toolkit"}, u)
+}
+
+func TestDo_retry(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":3}`))
+	}))
+	defer server.Close()
+
+	policy := NewExponentialBackoff(3)
+	resp, err := Do[user](context.Background(), NewHttpClient(), NewRequest(server.URL, http.MethodGet, RequestWithRetryPolicy(policy)))
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+	require.Equal(t, 3, resp.Data.ID)
+
+	calls = 0
+	_, err = Do[user](context.Background(), NewHttpClient(), NewRequest(server.URL, http.MethodPost, RequestWithRetryPolicy(policy)))
+	require.Error(t, err)
+	require.Equal(t, 1, calls)
+}
+
+func TestDo_retryTimeout(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{"id":4}`))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient(WithTimeout(50*time.Millisecond), WithRetryPolicy(NewExponentialBackoff(3)))
+	resp, err := Do[user](context.Background(), client, NewRequest(server.URL, http.MethodGet))
+	require.NoError(t, err)
+	require.Equal(t, int32(2), calls.Load())
+	require.Equal(t, 4, resp.Data.ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls.Store(0)
+	_, err = Do[user](ctx, client, NewRequest(server.URL, http.MethodGet))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, calls.Load())
+}
+
+func TestDo_noContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	resp, err := Do[user](context.Background(), NewHttpClient(), NewRequest(server.URL+"/users/1", http.MethodDelete))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	var u user
+	err = NewHttpClient().ExecuteRequest(context.Background(), NewRequest(server.URL, http.MethodPost, RequestWithDecodeValue(&u)))
+	require.NoError(t, err)
+}
+
+func TestDo_httpError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-trace", "t1")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"user not found"}`))
+	}))
+	defer server.Close()
+
+	var payload struct {
+		Message string `json:"message"`
+	}
+	_, err := Do[user](context.Background(), NewHttpClient(), NewRequest(server.URL+"/users/1", http.MethodGet, RequestWithErrorDecodeValue(&payload)))
+
+	var httpErr *HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+	require.Equal(t, http.MethodGet, httpErr.Method)
+	require.Equal(t, server.URL+"/users/1", httpErr.URL)
+	require.Equal(t, "t1", httpErr.Header.Get("x-trace"))
+	require.Empty(t, payload.Message)
+	require.Equal(t, "user not found", httpErr.Payload.(*struct {
+		Message string `json:"message"`
+	}).Message)
+}
+
+func TestDo_httpErrorConcurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"id":404}`))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient()
+	req := NewRequest(server.URL, http.MethodGet, RequestWithErrorDecodeValue(&user{}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Do[user](context.Background(), client, req)
+			var httpErr *HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			require.Equal(t, 404, httpErr.Payload.(*user).ID)
+		}()
+	}
+	wg.Wait()
+}
+
+// requestError mirrors apiError.RequestError, which this module cannot import.
+type requestError interface {
+	Code() string
+	Error() string
+	Status() int
+	Message() string
+}
+
+// fromError follows apiError.FromError for errors wrapping a RequestError.
+func fromError(err error) requestError {
+	var reqErr requestError
+	if errors.As(err, &reqErr) {
+		return reqErr
+	}
+	return nil
+}
+
+func TestHTTPError_requestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid partner api key sk_live_123"}`))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient()
+	_, err := Do[user](context.Background(), client, NewRequest(server.URL, http.MethodGet))
+	reqErr := fromError(fmt.Errorf("loading user: %w", err))
+	require.NotNil(t, reqErr)
+	require.Equal(t, http.StatusBadGateway, reqErr.Status())
+	require.Equal(t, "upstream_error", reqErr.Code())
+	require.NotContains(t, reqErr.Message(), "sk_live_123")
+
+	_, err = Do[user](context.Background(), client, NewRequest(server.URL, http.MethodGet, RequestWithUpstreamStatus()))
+	reqErr = fromError(err)
+	require.Equal(t, http.StatusUnauthorized, reqErr.Status())
+	require.Equal(t, "Unauthorized", reqErr.Message())
+}
+
+func TestWithMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		require.Equal(t, "id-1", r.Header.Get("x-request-id"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.Do(req)
+			})
+		}
+	}
+
+	client := NewHttpClient(WithMiddleware(
+		trace("first"),
+		HeaderMiddleware(map[string]string{"Authorization": "Bearer token"}),
+		RequestIDMiddleware("x-request-id", func() string { return "id-1" }),
+		trace("last"),
+	))
+	err := client.ExecuteRequest(context.Background(), NewRequest(server.URL, http.MethodGet))
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "last"}, order)
+}
+
+func TestRestClient_shared(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/users", r.URL.Path)
+		require.Equal(t, "toolkit", r.Header.Get("x-app"))
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient(WithBaseURL(server.URL+"/api/"), WithDefaultHeaders(map[string]string{"x-app": "toolkit"}))
+	req := NewRequest("/users", http.MethodGet)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := Do[user](context.Background(), client, req)
+			require.NoError(t, err)
+			require.Equal(t, 1, resp.Data.ID)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var transitions []CircuitState
+	client := NewHttpClient(WithCircuitBreaker(CircuitBreakerSettings{
+		WindowSize:  4,
+		MinRequests: 2,
+		Cooldown:    time.Hour,
+		OnStateChange: func(host string, from, to CircuitState) {
+			transitions = append(transitions, to)
+		},
+	}))
+
+	req := NewRequest(server.URL, http.MethodGet)
+	for i := 0; i < 2; i++ {
+		var httpErr *HTTPError
+		require.ErrorAs(t, client.ExecuteRequest(context.Background(), req), &httpErr)
+	}
+
+	err := client.ExecuteRequest(context.Background(), req)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.Equal(t, 2, calls)
+	require.Equal(t, []CircuitState{CircuitOpen}, transitions)
+}
+
+func TestWithCircuitBreaker_canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewHttpClient(WithCircuitBreaker(CircuitBreakerSettings{WindowSize: 4, MinRequests: 2, Cooldown: time.Hour}))
+	req := NewRequest(server.URL, http.MethodGet)
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		err := client.ExecuteRequest(ctx, req)
+		require.ErrorIs(t, err, context.Canceled)
+		require.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+}
+
+func TestRequestWithQueryAndPathParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/users/a%2Fb%20c", r.URL.RawPath)
+		require.Equal(t, "1", r.URL.Query().Get("page"))
+		require.Equal(t, "x&y=z", r.URL.Query().Get("q"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient(WithBaseURL(server.URL + "/v1"))
+	req := NewRequest("/users/{id}?page=1", http.MethodGet,
+		RequestWithPathParams(map[string]string{"id": "a/b c"}),
+		RequestWithQuery(url.Values{"q": {"x&y=z"}}),
+	)
+	require.NoError(t, client.ExecuteRequest(context.Background(), req))
+
+	err := client.ExecuteRequest(context.Background(), NewRequest("/users/{id}", http.MethodGet, RequestWithPathParams(map[string]string{})))
+	require.ErrorContains(t, err, "missing path param id")
+}
+
+func TestWithRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewHttpClient(WithRateLimit(RateLimitSettings{
+		Default: HostLimit{Rate: 50, Burst: 2, MaxInFlight: 2},
+	}))
+	req := NewRequest(server.URL, http.MethodGet)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, client.ExecuteRequest(context.Background(), req))
+		}()
+	}
+	wg.Wait()
+
+	require.LessOrEqual(t, maxInFlight, 2)
+	require.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, client.ExecuteRequest(ctx, req), context.Canceled)
+}
@@ -0,0 +1,237 @@
+package rest
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+const (
+	ContentTypeJSON      = "application/json"
+	ContentTypeXML       = "application/xml"
+	ContentTypeForm      = "application/x-www-form-urlencoded"
+	ContentTypeText      = "text/plain; charset=utf-8"
+	ContentTypeMultipart = "multipart/form-data"
+)
+
+// Encoder serializes a request body and reports the content type to send it with.
+type Encoder interface {
+	Encode(v any) (data []byte, contentType string, err error)
+}
+
+// Decoder deserializes a response body into v.
+type Decoder interface {
+	Decode(data []byte, v any) error
+}
+
+// JSONCodec is the default codec of the package.
+type JSONCodec struct{}
+
+func (JSONCodec) Encode(v any) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), ContentTypeJSON, nil
+}
+
+func (JSONCodec) Decode(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type XMLCodec struct{}
+
+func (XMLCodec) Encode(v any) ([]byte, string, error) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, ContentTypeXML, nil
+}
+
+func (XMLCodec) Decode(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
+
+// FormCodec encodes url.Values, map[string]string and map[string][]string as
+// application/x-www-form-urlencoded and decodes into *url.Values or *map[string]string.
+type FormCodec struct{}
+
+func (FormCodec) Encode(v any) ([]byte, string, error) {
+	var values url.Values
+	switch body := v.(type) {
+	case url.Values:
+		values = body
+	case map[string][]string:
+		values = body
+	case map[string]string:
+		values = make(url.Values, len(body))
+		for k, val := range body {
+			values.Set(k, val)
+		}
+	default:
+		return nil, "", fmt.Errorf("[restClient] form codec can't encode %T", v)
+	}
+	return []byte(values.Encode()), ContentTypeForm, nil
+}
+
+func (FormCodec) Decode(data []byte, v any) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	switch target := v.(type) {
+	case *url.Values:
+		*target = values
+	case *map[string]string:
+		*target = make(map[string]string, len(values))
+		for k := range values {
+			(*target)[k] = values.Get(k)
+		}
+	default:
+		return fmt.Errorf("[restClient] form codec can't decode into %T", v)
+	}
+	return nil
+}
+
+// TextCodec encodes strings, byte slices and encoding.TextMarshaler values as plain text
+// and decodes into *string, *[]byte or encoding.TextUnmarshaler.
+type TextCodec struct{}
+
+func (TextCodec) Encode(v any) ([]byte, string, error) {
+	switch body := v.(type) {
+	case string:
+		return []byte(body), ContentTypeText, nil
+	case []byte:
+		return body, ContentTypeText, nil
+	case encoding.TextMarshaler:
+		data, err := body.MarshalText()
+		return data, ContentTypeText, err
+	case fmt.Stringer:
+		return []byte(body.String()), ContentTypeText, nil
+	}
+	return nil, "", fmt.Errorf("[restClient] text codec can't encode %T", v)
+}
+
+func (TextCodec) Decode(data []byte, v any) error {
+	switch target := v.(type) {
+	case *string:
+		*target = string(data)
+	case *[]byte:
+		*target = append((*target)[:0], data...)
+	case encoding.TextUnmarshaler:
+		return target.UnmarshalText(data)
+	default:
+		return fmt.Errorf("[restClient] text codec can't decode into %T", v)
+	}
+	return nil
+}
+
+// MultipartBody is the body sent by RequestWithMultipart.
+type MultipartBody struct {
+	Fields map[string]string
+	Files  []MultipartFile
+}
+
+// MultipartFile is a file part of a MultipartBody.
+// ContentType defaults to application/octet-stream.
+type MultipartFile struct {
+	Field       string
+	FileName    string
+	ContentType string
+	Content     io.Reader
+}
+
+// MultipartEncoder encodes a MultipartBody as multipart/form-data.
+// File contents are read into memory so the body can be sent again on retries.
+type MultipartEncoder struct{}
+
+func (MultipartEncoder) Encode(v any) ([]byte, string, error) {
+	var body MultipartBody
+	switch b := v.(type) {
+	case MultipartBody:
+		body = b
+	case *MultipartBody:
+		body = *b
+	default:
+		return nil, "", fmt.Errorf("[restClient] multipart encoder can't encode %T", v)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for name, value := range body.Fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, "", err
+		}
+	}
+
+	for _, file := range body.Files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+			"name":     file.Field,
+			"filename": file.FileName,
+		}))
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err = io.Copy(part, file.Content); err != nil {
+			return nil, "", fmt.Errorf("[restClient] error reading multipart file %s: %v", file.FileName, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+type rawEncoder string
+
+func (contentType rawEncoder) Encode(v any) ([]byte, string, error) {
+	return v.([]byte), string(contentType), nil
+}
+
+// decoderFor picks the decoder matching the response content type, JSON being the fallback.
+// Text responses are only decoded as text into text targets, since many servers
+// send JSON labelled as text/plain.
+func decoderFor(contentType string, v any) Decoder {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return JSONCodec{}
+	}
+
+	switch {
+	case mediaType == ContentTypeXML, mediaType == "text/xml", strings.HasSuffix(mediaType, "+xml"):
+		return XMLCodec{}
+	case mediaType == ContentTypeForm:
+		return FormCodec{}
+	case strings.HasPrefix(mediaType, "text/") && isTextTarget(v):
+		return TextCodec{}
+	}
+	return JSONCodec{}
+}
+
+func isTextTarget(v any) bool {
+	switch v.(type) {
+	case *string, *[]byte, encoding.TextUnmarshaler:
+		return true
+	}
+	return false
+}
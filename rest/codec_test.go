@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestWithForm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, ContentTypeForm, r.Header.Get("content-type"))
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+
+		w.Header().Set("Content-Type", ContentTypeForm)
+		_, _ = w.Write([]byte("access_token=abc&expires_in=60"))
+	}))
+	defer server.Close()
+
+	var token url.Values
+	req := NewRequest(server.URL, http.MethodPost,
+		RequestWithForm(url.Values{"grant_type": {"client_credentials"}}),
+		RequestWithDecodeValue(&token),
+	)
+	require.NoError(t, NewHttpClient().ExecuteRequest(context.Background(), req))
+	require.Equal(t, "abc", token.Get("access_token"))
+}
+
+func TestRequestWithMultipart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		require.Equal(t, "42", r.FormValue("chat_id"))
+
+		file, header, err := r.FormFile("document")
+		require.NoError(t, err)
+		content, _ := io.ReadAll(file)
+		require.Equal(t, "report.csv", header.Filename)
+		require.Equal(t, "a,b", string(content))
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("uploaded"))
+	}))
+	defer server.Close()
+
+	req := NewRequest(server.URL, http.MethodPost, RequestWithMultipart(MultipartBody{
+		Fields: map[string]string{"chat_id": "42"},
+		Files:  []MultipartFile{{Field: "document", FileName: "report.csv", Content: strings.NewReader("a,b")}},
+	}))
+	resp, err := Do[string](context.Background(), NewHttpClient(), req)
+	require.NoError(t, err)
+	require.Equal(t, "uploaded", resp.Data)
+}
+
+func TestDo_xml(t *testing.T) {
+	type item struct {
+		XMLName xml.Name `xml:"item"`
+		Name    string   `xml:"name"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		require.Equal(t, ContentTypeXML, r.Header.Get("content-type"))
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	req := NewRequest(server.URL, http.MethodPost, RequestWithBody(item{Name: "toolkit"}), RequestWithEncoder(XMLCodec{}))
+	resp, err := Do[item](context.Background(), NewHttpClient(), req)
+	require.NoError(t, err)
+	require.Equal(t, "toolkit", resp.Data.Name)
+}
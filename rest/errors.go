@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// maxErrorBodySize caps how much of a failed response body is kept in HTTPError.
+const maxErrorBodySize = 4 << 10 // 4KB
+
+// HTTPError is returned when the server answers with a non 2xx status.
+// Use errors.As to retrieve it:
+//
+//	var httpErr *rest.HTTPError
+//	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound { ... }
+//
+// HTTPError satisfies apiError.RequestError, so it can be returned as is to report an
+// upstream failure: it is then a 502 with a generic message, the upstream body never
+// reaching our own callers. RequestWithUpstreamStatus keeps the upstream status instead.
+type HTTPError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Header     http.Header
+	// Body holds the response body, truncated to 4KB.
+	Body []byte
+	// Payload is the decoded error body when RequestWithErrorDecodeValue was used.
+	Payload any
+	// UpstreamStatus is set by RequestWithUpstreamStatus.
+	UpstreamStatus bool
+}
+
+func newHTTPError(r *Request, req *http.Request, resp *http.Response, body []byte) *HTTPError {
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		URL:        req.URL.Redacted(),
+		Header:     resp.Header,
+		Body:       body,
+
+		UpstreamStatus: r.options.upstreamStatus,
+	}
+	if len(body) > maxErrorBodySize {
+		httpErr.Body = body[:maxErrorBodySize]
+	}
+	if target := r.options.errorDecode; target != nil && len(body) > 0 {
+		payload := newPayload(target)
+		if err := r.decode(resp.Header, body, payload); err == nil {
+			httpErr.Payload = payload
+		}
+	}
+	return httpErr
+}
+
+// newPayload allocates a value of the type target points to, so concurrent sends of a
+// request never decode into the same memory.
+func newPayload(target any) any {
+	t := reflect.TypeOf(target)
+	if t.Kind() != reflect.Pointer {
+		return reflect.New(t).Interface()
+	}
+	return reflect.New(t.Elem()).Interface()
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("[restClient] %s %s failed with status %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// Code returns the error code used when the error is re-surfaced as an apiError.RequestError.
+func (e *HTTPError) Code() string {
+	return "upstream_error"
+}
+
+// Status returns 502 Bad Gateway, or the status sent by the server with RequestWithUpstreamStatus.
+// Use StatusCode to inspect the upstream status.
+func (e *HTTPError) Status() int {
+	if e.UpstreamStatus {
+		return e.StatusCode
+	}
+	return http.StatusBadGateway
+}
+
+// Message returns a generic message, the upstream body may hold details
+// of the partner that must not be sent to our callers.
+func (e *HTTPError) Message() string {
+	if e.UpstreamStatus {
+		return http.StatusText(e.StatusCode)
+	}
+	return "upstream service error"
+}
@@ -0,0 +1,139 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type hedging struct {
+	delay       time.Duration
+	maxRequests int
+}
+
+// RequestWithHedging sends another copy of the request every time delay elapses
+// without an answer, up to maxRequests copies in total, and keeps the first success.
+// The remaining copies are cancelled. A good delay is the p95 latency of the endpoint.
+// Only idempotent requests are hedged, see ExponentialBackoff for the rules.
+func RequestWithHedging(delay time.Duration, maxRequests int) Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		c.hedging = &hedging{delay: delay, maxRequests: maxRequests}
+	})
+}
+
+func (req *Request) hedged() bool {
+	h := req.options.hedging
+	if h == nil || h.maxRequests < 2 || req.options.stream != nil {
+		return false
+	}
+
+	probe := &http.Request{Method: req.method, Header: make(http.Header)}
+	for k, v := range req.options.headers {
+		probe.Header.Set(k, v)
+	}
+	return isIdempotent(probe)
+}
+
+func (client *RestClient) sendHedged(ctx context.Context, req *Request) (*rawResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type outcome struct {
+		raw *rawResponse
+		err error
+	}
+	h := req.options.hedging
+	outcomes := make(chan outcome, h.maxRequests)
+	launch := func() {
+		go func() {
+			raw, err := client.sendOnce(ctx, req)
+			outcomes <- outcome{raw, err}
+		}()
+	}
+
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+
+	launch()
+	launched, failed := 1, 0
+	var lastErr error
+	for {
+		select {
+		case o := <-outcomes:
+			if o.err == nil || isDefinitive(o.err) {
+				return o.raw, o.err
+			}
+			lastErr = o.err
+			if failed++; failed == launched {
+				if launched == h.maxRequests {
+					return nil, lastErr
+				}
+				// every copy failed already, no point in waiting for the delay
+				launch()
+				launched++
+				timer.Reset(h.delay)
+			}
+		case <-timer.C:
+			if launched < h.maxRequests {
+				launch()
+				launched++
+				timer.Reset(h.delay)
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// isDefinitive tells whether the server rejected the request itself,
+// in which case another copy would get the same answer.
+func isDefinitive(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) &&
+		httpErr.StatusCode < http.StatusInternalServerError &&
+		httpErr.StatusCode != http.StatusTooManyRequests
+}
+
+// Result is the outcome of one request sent by FanOut.
+type Result[T any] struct {
+	Response *Response[T]
+	Err      error
+}
+
+// FanOut sends every request concurrently, at most limit at a time (no limit when
+// limit <= 0), and returns their results in the order of reqs.
+// A failed request does not stop the others.
+func FanOut[T any](ctx context.Context, client *RestClient, reqs []*Request, limit int) []Result[T] {
+	if limit <= 0 || limit > len(reqs) {
+		limit = len(reqs)
+	}
+
+	results := make([]Result[T], len(reqs))
+	slots := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(reqs); j++ {
+				results[j].Err = ctx.Err()
+			}
+			wg.Wait()
+			return results
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			resp, err := Do[T](ctx, client, req)
+			results[i] = Result[T]{Response: resp, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestWithHedging(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-time.After(2 * time.Second):
+			case <-r.Context().Done():
+			}
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":9}`))
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	req := NewRequest(server.URL, http.MethodGet, RequestWithHedging(50*time.Millisecond, 2))
+	resp, err := Do[user](context.Background(), NewHttpClient(), req)
+	require.NoError(t, err)
+	require.Equal(t, 9, resp.Data.ID)
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestFanOut(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if r.URL.Path == "/users/3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"id":%s}`, r.URL.Path[len("/users/"):])
+	}))
+	defer server.Close()
+
+	var reqs []*Request
+	for i := 1; i <= 5; i++ {
+		reqs = append(reqs, NewRequest(fmt.Sprintf("%s/users/%d", server.URL, i), http.MethodGet))
+	}
+
+	results := FanOut[user](context.Background(), NewHttpClient(), reqs, 2)
+	require.Len(t, results, 5)
+	for i, result := range results {
+		if i == 2 {
+			require.Error(t, result.Err)
+			continue
+		}
+		require.NoError(t, result.Err)
+		require.Equal(t, i+1, result.Response.Data.ID)
+	}
+	require.LessOrEqual(t, maxInFlight.Load(), int32(2))
+}
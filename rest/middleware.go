@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+)
+
+// Doer sends an HTTP request and returns its response, *http.Client is a Doer.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function into a Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer to run code around every attempt made by the client.
+// Middlewares registered with WithMiddleware run in the order they were given,
+// the first one being the outermost.
+type Middleware func(next Doer) Doer
+
+func chain(doer Doer, middlewares []Middleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		doer = middlewares[i](doer)
+	}
+	return doer
+}
+
+// Logger is the logging contract used by LoggingMiddleware, *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// LoggingMiddleware logs method, url, status and duration of every request.
+func LoggingMiddleware(logger Logger) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Do(req)
+			if err != nil {
+				logger.Printf("[restClient] %s %s failed after %s: %v", req.Method, req.URL.Redacted(), time.Since(start), err)
+				return resp, err
+			}
+			logger.Printf("[restClient] %s %s %d %s", req.Method, req.URL.Redacted(), resp.StatusCode, time.Since(start))
+			return resp, nil
+		})
+	}
+}
+
+// HeaderMiddleware sets the headers on every request, overriding existing values.
+func HeaderMiddleware(headers map[string]string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+// RequestIDMiddleware sets header to a value produced by generate when the request
+// does not carry one yet.
+func RequestIDMiddleware(header string, generate func() string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) == "" {
+				req.Header.Set(header, generate())
+			}
+			return next.Do(req)
+		})
+	}
+}
@@ -2,16 +2,28 @@ package rest
 
 import (
 	"errors"
+	"io"
 	"net/http"
+	"net/url"
 	"time"
 )
 
 type clientReQuestOptions struct {
 	body              any
+	encoder           Encoder
+	stream            io.Reader
+	streamContentType string
 	decode            any
+	decoder           Decoder
+	errorDecode       any
+	upstreamStatus    bool
 	headers           map[string]string
+	query             url.Values
+	pathParams        map[string]string
 	timeout           time.Duration
 	checkRedirectFunc func(*http.Request, []*http.Request) error
+	retryPolicy       RetryPolicy
+	hedging           *hedging
 }
 
 type OptionFunc func(*clientReQuestOptions)
@@ -31,12 +43,76 @@ func (f OptionFunc) Apply(client *clientReQuestOptions) {
 	f(client)
 }
 
+// RequestWithBody sets the request body, encoded as JSON unless RequestWithEncoder is used.
 func RequestWithBody(body any) Option {
 	return OptionFunc(func(c *clientReQuestOptions) {
 		c.body = body
 	})
 }
 
+// RequestWithEncoder sets the encoder used for the body given with RequestWithBody.
+func RequestWithEncoder(encoder Encoder) Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		c.encoder = encoder
+	})
+}
+
+// RequestWithForm sends values as an application/x-www-form-urlencoded body.
+func RequestWithForm(values url.Values) Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		c.body = values
+		c.encoder = FormCodec{}
+	})
+}
+
+// RequestWithMultipart sends body as multipart/form-data.
+func RequestWithMultipart(body MultipartBody) Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		c.body = body
+		c.encoder = MultipartEncoder{}
+	})
+}
+
+// RequestWithRawBody sends data as is with the given content type.
+func RequestWithRawBody(data []byte, contentType string) Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		c.body = data
+		c.encoder = rawEncoder(contentType)
+	})
+}
+
+// RequestWithBodyReader streams body to the server without buffering it.
+// A streamed body can only be read once, so the request is never retried.
+func RequestWithBodyReader(body io.Reader, contentType string) Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		c.stream = body
+		c.streamContentType = contentType
+	})
+}
+
+// RequestWithQuery adds values to the query string of the request url,
+// keeping the parameters already present in it.
+func RequestWithQuery(values url.Values) Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		query := make(url.Values, len(c.query)+len(values))
+		for k, v := range c.query {
+			query[k] = append(query[k], v...)
+		}
+		for k, v := range values {
+			query[k] = append(query[k], v...)
+		}
+		c.query = query
+	})
+}
+
+// RequestWithPathParams fills the {name} placeholders of the request url, e.g. /users/{id},
+// escaping every value as a single path segment.
+func RequestWithPathParams(params map[string]string) Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		c.pathParams = params
+	})
+}
+
 func RequestWithHeaders(headers map[string]string) Option {
 	return OptionFunc(func(c *clientReQuestOptions) {
 		c.headers = headers
@@ -49,6 +125,32 @@ func RequestWithDecodeValue(decode any) Option {
 	})
 }
 
+// RequestWithErrorDecodeValue decodes the body of a non 2xx response into a new value of
+// the type decode points to, available as HTTPError.Payload. decode only gives the type and
+// is never written, so the request can be sent concurrently.
+func RequestWithErrorDecodeValue(decode any) Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		c.errorDecode = decode
+	})
+}
+
+// RequestWithUpstreamStatus makes the HTTPError of the request report the status sent
+// by the server when re-surfaced as an apiError.RequestError, instead of 502.
+// Only use it when the upstream status makes sense to your own callers.
+func RequestWithUpstreamStatus() Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		c.upstreamStatus = true
+	})
+}
+
+// RequestWithDecoder forces the decoder used for the response body,
+// by default it is picked from the response Content-Type.
+func RequestWithDecoder(decoder Decoder) Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		c.decoder = decoder
+	})
+}
+
 func RequestWithTimeout(timeout time.Duration) Option {
 	return OptionFunc(func(c *clientReQuestOptions) {
 		c.timeout = timeout
@@ -60,3 +162,11 @@ func RequestWithCheckRedirectFunc(fn func(*http.Request, []*http.Request) error)
 		c.checkRedirectFunc = fn
 	})
 }
+
+// RequestWithRetryPolicy retries the request according to the policy,
+// see NewExponentialBackoff for the default implementation.
+func RequestWithRetryPolicy(policy RetryPolicy) Option {
+	return OptionFunc(func(c *clientReQuestOptions) {
+		c.retryPolicy = policy
+	})
+}
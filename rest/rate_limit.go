@@ -0,0 +1,209 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HostLimit bounds the traffic sent to a single host. Zero values mean no limit.
+type HostLimit struct {
+	// Rate is the number of requests per second allowed on average.
+	Rate float64
+	// Burst is the number of requests that can be sent at once, 1 by default.
+	Burst int
+	// MaxInFlight caps the number of requests waiting for or reading a response.
+	MaxInFlight int
+}
+
+// RateLimitSettings configures WithRateLimit.
+type RateLimitSettings struct {
+	// Default applies to hosts missing from Hosts.
+	Default HostLimit
+	// Hosts holds the limits of specific hosts, keyed by host as in url.URL.Host.
+	Hosts map[string]HostLimit
+	// Adaptive halves the rate of a host every time it answers 429 Too Many Requests,
+	// pausing it for the Retry-After delay when sent, and restores the rate gradually
+	// on the following successful responses.
+	Adaptive bool
+}
+
+func (s RateLimitSettings) limitFor(host string) HostLimit {
+	if limit, ok := s.Hosts[host]; ok {
+		return limit
+	}
+	return s.Default
+}
+
+// WithRateLimit throttles the requests sent to each host.
+// Requests over the budget wait for their turn, respecting their context, instead of failing.
+func WithRateLimit(settings RateLimitSettings) ClientOption {
+	return WithMiddleware(RateLimitMiddleware(settings))
+}
+
+// RateLimitMiddleware applies a token bucket and a max in flight semaphore per host.
+func RateLimitMiddleware(settings RateLimitSettings) Middleware {
+	var mu sync.Mutex
+	limiters := make(map[string]*hostLimiter)
+	limiterFor := func(host string) *hostLimiter {
+		mu.Lock()
+		defer mu.Unlock()
+
+		limiter, ok := limiters[host]
+		if !ok {
+			limiter = newHostLimiter(settings.limitFor(host))
+			limiters[host] = limiter
+		}
+		return limiter
+	}
+
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			limiter := limiterFor(req.URL.Host)
+			release, err := limiter.acquire(req.Context())
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := next.Do(req)
+			if err != nil {
+				release()
+				return resp, err
+			}
+
+			if settings.Adaptive {
+				if resp.StatusCode == http.StatusTooManyRequests {
+					wait, _ := retryAfter(resp.Header.Get("Retry-After"))
+					limiter.slowDown(wait)
+				} else if resp.StatusCode < http.StatusBadRequest {
+					limiter.speedUp()
+				}
+			}
+
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+			return resp, nil
+		})
+	}
+}
+
+type hostLimiter struct {
+	mu          sync.Mutex
+	limit       HostLimit
+	rate        float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	inFlight    chan struct{}
+}
+
+func newHostLimiter(limit HostLimit) *hostLimiter {
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+
+	limiter := &hostLimiter{
+		limit:  limit,
+		rate:   limit.Rate,
+		tokens: float64(limit.Burst),
+		last:   time.Now(),
+	}
+	if limit.MaxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	return limiter
+}
+
+// acquire waits for a token and a free in flight slot. release must be called
+// once the request is over.
+func (l *hostLimiter) acquire(ctx context.Context) (release func(), err error) {
+	if err = l.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if l.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-l.inFlight })
+	}, nil
+}
+
+// wait reserves a token, sleeping until it is available.
+func (l *hostLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	var delay time.Duration
+	if now.Before(l.pausedUntil) {
+		delay = l.pausedUntil.Sub(now)
+	}
+
+	if l.rate > 0 {
+		l.refill(now)
+		l.tokens--
+		if l.tokens < 0 {
+			delay += time.Duration(-l.tokens / l.rate * float64(time.Second))
+		}
+	}
+	l.mu.Unlock()
+
+	if err := sleep(ctx, delay); err != nil {
+		if l.rate > 0 {
+			l.mu.Lock()
+			l.tokens++
+			l.mu.Unlock()
+		}
+		return err
+	}
+	return nil
+}
+
+// refill adds the tokens earned since the last call, callers must hold the lock.
+func (l *hostLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	l.last = now
+	l.tokens = math.Min(float64(l.limit.Burst), l.tokens+elapsed*l.rate)
+}
+
+func (l *hostLimiter) slowDown(pause time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(pause); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	if l.rate > 0 {
+		l.refill(time.Now())
+		l.rate = math.Max(l.rate/2, l.limit.Rate/16)
+	}
+}
+
+func (l *hostLimiter) speedUp() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate > 0 && l.rate < l.limit.Rate {
+		l.refill(time.Now())
+		l.rate = math.Min(l.rate*1.1, l.limit.Rate)
+	}
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// Request describes a single HTTP call: the target url, the method and the
+// options that shape the body, headers and decoding.
+// A Request is immutable once built, so it can be reused and sent concurrently
+// with Do or RestClient.ExecuteRequest. The exception is the target of
+// RequestWithDecodeValue, written by every ExecuteRequest: prefer Do for shared requests.
+// Use With to derive a variant of it.
+type Request struct {
+	url     string
+	method  string
+	options clientReQuestOptions
+}
+
+// NewRequest creates a Request for the given url and method.
+// A relative url is resolved against the client base url, see WithBaseURL.
+func NewRequest(url, method string, options ...Option) *Request {
+	req := &Request{url: url, method: method}
+	for _, o := range options {
+		o.Apply(&req.options)
+	}
+	return req
+}
+
+// URL returns the url the request will be sent to.
+func (req *Request) URL() string {
+	return req.url
+}
+
+// Method returns the HTTP method of the request.
+func (req *Request) Method() string {
+	return req.method
+}
+
+// With returns a copy of the request with the options applied on top of the current ones.
+func (req *Request) With(options ...Option) *Request {
+	derived := *req
+	for _, o := range options {
+		o.Apply(&derived.options)
+	}
+	return &derived
+}
+
+// requestBody is the encoded body of a request. Encoded bodies are kept in memory
+// so they can be sent again on retries, streams are sent as they are.
+type requestBody struct {
+	data        []byte
+	stream      io.Reader
+	contentType string
+}
+
+func (b requestBody) reader() io.Reader {
+	if b.stream != nil {
+		return b.stream
+	}
+	return bytes.NewReader(b.data)
+}
+
+func (req *Request) encodeBody() (requestBody, error) {
+	if req.options.stream != nil {
+		return requestBody{stream: req.options.stream, contentType: req.options.streamContentType}, nil
+	}
+	if req.options.body == nil {
+		return requestBody{contentType: ContentTypeJSON}, nil
+	}
+
+	encoder := req.options.encoder
+	if encoder == nil {
+		encoder = JSONCodec{}
+	}
+
+	data, contentType, err := encoder.Encode(req.options.body)
+	if err != nil {
+		return requestBody{}, fmt.Errorf("[restClient] error encode request body: %v", err)
+	}
+	return requestBody{data: data, contentType: contentType}, nil
+}
+
+func (req *Request) decode(header http.Header, data []byte, v any) error {
+	decoder := req.options.decoder
+	if decoder == nil {
+		decoder = decoderFor(header.Get("Content-Type"), v)
+	}
+	return decoder.Decode(data, v)
+}
+
+// expandPath replaces the {name} placeholders of the url with the escaped path params.
+// Urls of requests without path params are left untouched.
+func (req *Request) expandPath() (string, error) {
+	if req.options.pathParams == nil {
+		return req.url, nil
+	}
+
+	var missing string
+	path := pathParamPattern.ReplaceAllStringFunc(req.url, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		value, ok := req.options.pathParams[name]
+		if !ok {
+			missing = name
+			return placeholder
+		}
+		return url.PathEscape(value)
+	})
+
+	if missing != "" {
+		return "", fmt.Errorf("[restClient] missing path param %s for %s", missing, req.url)
+	}
+	return path, nil
+}
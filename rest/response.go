@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Response is the typed result of Do.
+// Data holds the decoded body, Body keeps the raw bytes as received.
+type Response[T any] struct {
+	Data       T
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration
+}
+
+// Do sends the request using the client and decodes the response body into T.
+// The decoder is picked from the response Content-Type unless RequestWithDecoder was used.
+// An empty body leaves Data as the zero value of T.
+func Do[T any](ctx context.Context, client *RestClient, req *Request) (*Response[T], error) {
+	if req == nil {
+		return nil, errors.New("[restClient] request must not be nil")
+	}
+
+	raw, err := client.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &Response[T]{
+		StatusCode: raw.statusCode,
+		Header:     raw.header,
+		Body:       raw.body,
+		Duration:   raw.duration,
+	}
+
+	if len(raw.body) > 0 {
+		if err = req.decode(raw.header, raw.body, &response.Data); err != nil {
+			return response, fmt.Errorf("error trying to Unmarshal response: %v", err)
+		}
+	}
+	return response, nil
+}
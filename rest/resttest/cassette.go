@@ -0,0 +1,60 @@
+// Package resttest provides utilities to test code built on top of the rest package:
+// a Recorder capturing real interactions to a cassette file, a Replayer serving them
+// back offline and a mock Server matching requests against expectations.
+package resttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Cassette is the file format shared by Recorder and Replayer.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// LoadCassette reads a cassette file written by Recorder.Save.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("[resttest] error reading cassette %s: %v", path, err)
+	}
+
+	var cassette Cassette
+	if err = json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("[resttest] error decoding cassette %s: %v", path, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to path as indented JSON.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("[resttest] error writing cassette %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package resttest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/alabuta-source/toolkit/rest"
+)
+
+const redactedValue = "[REDACTED]"
+
+// DefaultRedactedHeaders are the headers masked by NewRecorder.
+var DefaultRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// TelegramTokenPattern matches the bot token in the path of the Telegram Bot API urls.
+var TelegramTokenPattern = regexp.MustCompile(`/bot(\d+:[\w-]+)`)
+
+// DefaultRedactedURLPatterns are the url parts masked by NewRecorder.
+var DefaultRedactedURLPatterns = []*regexp.Regexp{TelegramTokenPattern}
+
+// Recorder captures the interactions made through a rest.RestClient.
+// Register it with rest.WithMiddleware(recorder.Middleware()) and call Save once done.
+type Recorder struct {
+	mu       sync.Mutex
+	path     string
+	redact   []string
+	urls     []*regexp.Regexp
+	cassette Cassette
+}
+
+// NewRecorder creates a Recorder writing to path. The values of DefaultRedactedHeaders
+// and of the extra redact headers are masked in the cassette.
+func NewRecorder(path string, redact ...string) *Recorder {
+	return &Recorder{
+		path:   path,
+		redact: append(append([]string{}, DefaultRedactedHeaders...), redact...),
+		urls:   append([]*regexp.Regexp{}, DefaultRedactedURLPatterns...),
+	}
+}
+
+// RedactURL masks the parts of the recorded urls matching patterns, in addition to
+// DefaultRedactedURLPatterns: the first group of a match when the pattern has one,
+// the whole match otherwise, e.g. regexp.MustCompile(`[?&]api_key=([^&]+)`).
+// The Replayer matches masked parts against any value.
+func (r *Recorder) RedactURL(patterns ...*regexp.Regexp) *Recorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.urls = append(r.urls, patterns...)
+	return r
+}
+
+// Middleware returns the middleware recording every request and its response.
+func (r *Recorder) Middleware() rest.Middleware {
+	return func(next rest.Doer) rest.Doer {
+		return rest.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			reqBody, err := readRequestBody(req)
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := next.Do(req)
+			if err != nil {
+				return resp, err
+			}
+
+			respBody, err := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+			r.add(Interaction{
+				Request: RecordedRequest{
+					Method: req.Method,
+					URL:    r.redactURL(req.URL.String()),
+					Header: r.redactHeader(req.Header),
+					Body:   string(reqBody),
+				},
+				Response: RecordedResponse{
+					StatusCode: resp.StatusCode,
+					Header:     r.redactHeader(resp.Header),
+					Body:       string(respBody),
+				},
+			})
+			return resp, nil
+		})
+	}
+}
+
+// Interactions returns a copy of what was recorded so far.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction{}, r.cassette.Interactions...)
+}
+
+// Save writes the recorded interactions to the cassette file.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+func (r *Recorder) add(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+}
+
+func (r *Recorder) redactURL(url string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, pattern := range r.urls {
+		url = pattern.ReplaceAllStringFunc(url, func(match string) string {
+			groups := pattern.FindStringSubmatchIndex(match)
+			if len(groups) < 4 || groups[2] < 0 {
+				return redactedValue
+			}
+			return match[:groups[2]] + redactedValue + match[groups[3]:]
+		})
+	}
+	return url
+}
+
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name := range redacted {
+		for _, sensitive := range r.redact {
+			if strings.EqualFold(name, sensitive) {
+				redacted[name] = []string{redactedValue}
+			}
+		}
+	}
+	return redacted
+}
+
+// readRequestBody reads the request body without consuming it.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
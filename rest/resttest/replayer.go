@@ -0,0 +1,98 @@
+package resttest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Replayer is an http.RoundTripper serving the interactions of a cassette,
+// use it with rest.WithTransport to test offline.
+// Requests are matched by method and url, the url parts masked when recording matching
+// any value. Interactions sharing both are served in the order they were recorded and
+// each one is served only once.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer loads the cassette at path.
+func NewReplayer(path string) (*Replayer, error) {
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewCassetteReplayer(cassette), nil
+}
+
+// NewCassetteReplayer serves the interactions of an in memory cassette.
+func NewCassetteReplayer(cassette *Cassette) *Replayer {
+	return &Replayer{
+		interactions: cassette.Interactions,
+		used:         make([]bool, len(cassette.Interactions)),
+	}
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Request.Method != req.Method || !matchURL(interaction.Request.URL, req.URL.String()) {
+			continue
+		}
+		r.used[i] = true
+
+		recorded := interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("[resttest] no recorded interaction left for %s %s", req.Method, req.URL)
+}
+
+// matchURL compares a recorded url with the one of a request, the parts masked by
+// the Recorder matching any value.
+func matchURL(recorded, url string) bool {
+	if !strings.Contains(recorded, redactedValue) {
+		return recorded == url
+	}
+
+	parts := strings.Split(recorded, redactedValue)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	matched, _ := regexp.MatchString("^"+strings.Join(parts, ".+?")+"$", url)
+	return matched
+}
+
+// Remaining returns how many interactions were not served yet.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var remaining int
+	for _, used := range r.used {
+		if !used {
+			remaining++
+		}
+	}
+	return remaining
+}
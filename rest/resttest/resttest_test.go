@@ -0,0 +1,93 @@
+package resttest
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/alabuta-source/toolkit/rest"
+	"github.com/stretchr/testify/require"
+)
+
+type charge struct {
+	Txid   string `json:"txid"`
+	Status string `json:"status"`
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := NewServer(t)
+	server.Expect(http.MethodPost, "/v2/cob").
+		WithHeader("Authorization", "Bearer secret").
+		WithJSONBody(map[string]string{"chave": "key"}).
+		RespondJSON(http.StatusCreated, charge{Txid: "tx1", Status: "ATIVA"})
+	server.Expect(http.MethodGet, "/v2/cob/tx1").
+		WithQuery(url.Values{"revisao": {"0"}}).
+		RespondJSON(http.StatusOK, charge{Txid: "tx1", Status: "CONCLUIDA"})
+
+	path := filepath.Join(t.TempDir(), "pix.json")
+	recorder := NewRecorder(path)
+	client := rest.NewHttpClient(
+		rest.WithBaseURL(server.URL),
+		rest.WithDefaultHeaders(map[string]string{"Authorization": "Bearer secret"}),
+		rest.WithMiddleware(recorder.Middleware()),
+	)
+	runPixFlow(t, client)
+	require.NoError(t, recorder.Save())
+
+	cassette, err := LoadCassette(path)
+	require.NoError(t, err)
+	require.Len(t, cassette.Interactions, 2)
+	require.Equal(t, []string{redactedValue}, cassette.Interactions[0].Request.Header["Authorization"])
+
+	replayer, err := NewReplayer(path)
+	require.NoError(t, err)
+	runPixFlow(t, rest.NewHttpClient(rest.WithBaseURL(server.URL), rest.WithTransport(replayer)))
+	require.Zero(t, replayer.Remaining())
+}
+
+func runPixFlow(t *testing.T, client *rest.RestClient) {
+	created, err := rest.Do[charge](context.Background(), client,
+		rest.NewRequest("/v2/cob", http.MethodPost, rest.RequestWithBody(map[string]string{"chave": "key"})))
+	require.NoError(t, err)
+	require.Equal(t, "ATIVA", created.Data.Status)
+
+	detail, err := rest.Do[charge](context.Background(), client,
+		rest.NewRequest("/v2/cob/{txid}", http.MethodGet,
+			rest.RequestWithPathParams(map[string]string{"txid": created.Data.Txid}),
+			rest.RequestWithQuery(url.Values{"revisao": {"0"}}),
+		))
+	require.NoError(t, err)
+	require.Equal(t, "CONCLUIDA", detail.Data.Status)
+}
+
+func TestRecorder_redactURL(t *testing.T) {
+	server := NewServer(t)
+	server.Expect(http.MethodPost, "/bot123456:SECRET-token_1/sendMessage").
+		WithQuery(url.Values{"api_key": {"k1"}}).
+		RespondJSON(http.StatusOK, map[string]bool{"ok": true})
+
+	path := filepath.Join(t.TempDir(), "telegram.json")
+	recorder := NewRecorder(path).RedactURL(regexp.MustCompile(`[?&]api_key=([^&]+)`))
+	send := func(client *rest.RestClient) {
+		_, err := rest.Do[map[string]bool](context.Background(), client,
+			rest.NewRequest(server.URL+"/bot123456:SECRET-token_1/sendMessage?api_key=k1", http.MethodPost))
+		require.NoError(t, err)
+	}
+	send(rest.NewHttpClient(rest.WithMiddleware(recorder.Middleware())))
+	require.NoError(t, recorder.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "SECRET")
+	require.NotContains(t, string(data), "k1")
+	require.Contains(t, string(data), "/bot[REDACTED]/sendMessage?api_key=[REDACTED]")
+
+	replayer, err := NewReplayer(path)
+	require.NoError(t, err)
+	send(rest.NewHttpClient(rest.WithTransport(replayer)))
+	require.Zero(t, replayer.Remaining())
+}
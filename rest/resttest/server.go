@@ -0,0 +1,211 @@
+package resttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// Server is an httptest server answering requests with the response of the first
+// matching expectation. Unexpected requests fail the test with a 501 answer and
+// expectations not met when the test ends fail it as well.
+type Server struct {
+	*httptest.Server
+	t            testing.TB
+	mu           sync.Mutex
+	expectations []*Expectation
+}
+
+// NewServer starts a Server closed automatically at the end of the test.
+func NewServer(t testing.TB) *Server {
+	s := &Server{t: t}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(func() {
+		s.Close()
+		s.AssertExpectations()
+	})
+	return s
+}
+
+// Expect registers an expectation for method and path, answered with 200 and no body
+// until configured otherwise.
+func (s *Server) Expect(method, path string) *Expectation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := &Expectation{
+		method: method,
+		path:   path,
+		times:  1,
+		status: http.StatusOK,
+		header: make(http.Header),
+	}
+	s.expectations = append(s.expectations, e)
+	return e
+}
+
+// AssertExpectations fails the test for every expectation not called the expected number of times.
+func (s *Server) AssertExpectations() {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.expectations {
+		if e.times > 0 && e.calls != e.times {
+			s.t.Errorf("[resttest] expected %s %s to be called %d time(s), got %d", e.method, e.path, e.times, e.calls)
+		}
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	var matched *Expectation
+	for _, e := range s.expectations {
+		if (e.times <= 0 || e.calls < e.times) && e.matches(r, body) {
+			e.calls++
+			matched = e
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if matched == nil {
+		s.t.Errorf("[resttest] unexpected request %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	for k, v := range matched.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(matched.status)
+	_, _ = w.Write(matched.body)
+}
+
+// Expectation describes a request the Server should receive and how to answer it.
+type Expectation struct {
+	method   string
+	path     string
+	query    url.Values
+	headers  map[string]string
+	reqBody  []byte
+	jsonBody bool
+
+	times int
+	calls int
+
+	status int
+	header http.Header
+	body   []byte
+}
+
+// WithQuery requires the request to carry the query parameters.
+func (e *Expectation) WithQuery(query url.Values) *Expectation {
+	e.query = query
+	return e
+}
+
+// WithHeader requires the request header name to be value.
+func (e *Expectation) WithHeader(name, value string) *Expectation {
+	if e.headers == nil {
+		e.headers = make(map[string]string)
+	}
+	e.headers[name] = value
+	return e
+}
+
+// WithBody requires the request body to be exactly body.
+func (e *Expectation) WithBody(body string) *Expectation {
+	e.reqBody, e.jsonBody = []byte(body), false
+	return e
+}
+
+// WithJSONBody requires the request body to be JSON equivalent to v.
+func (e *Expectation) WithJSONBody(v any) *Expectation {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	e.reqBody, e.jsonBody = data, true
+	return e
+}
+
+// Times sets how many times the expectation must be met, 1 by default.
+// Zero allows any number of calls.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Respond sets the status and raw body of the answer.
+func (e *Expectation) Respond(status int, body string) *Expectation {
+	e.status, e.body = status, []byte(body)
+	return e
+}
+
+// RespondJSON sets the status and the JSON encoded body of the answer.
+func (e *Expectation) RespondJSON(status int, v any) *Expectation {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	e.status, e.body = status, data
+	e.header.Set("Content-Type", "application/json")
+	return e
+}
+
+// RespondHeader adds a header to the answer.
+func (e *Expectation) RespondHeader(name, value string) *Expectation {
+	e.header.Add(name, value)
+	return e
+}
+
+func (e *Expectation) matches(r *http.Request, body []byte) bool {
+	if r.Method != e.method || r.URL.Path != e.path {
+		return false
+	}
+
+	query := r.URL.Query()
+	for k, values := range e.query {
+		got := query[k]
+		if len(got) != len(values) {
+			return false
+		}
+		for i := range values {
+			if got[i] != values[i] {
+				return false
+			}
+		}
+	}
+
+	for name, value := range e.headers {
+		if r.Header.Get(name) != value {
+			return false
+		}
+	}
+
+	if e.reqBody == nil {
+		return true
+	}
+	if e.jsonBody {
+		return jsonEqual(e.reqBody, body)
+	}
+	return bytes.Equal(e.reqBody, body)
+}
+
+func jsonEqual(expected, actual []byte) bool {
+	var want, got any
+	if json.Unmarshal(expected, &want) != nil || json.Unmarshal(actual, &got) != nil {
+		return false
+	}
+	wantData, _ := json.Marshal(want)
+	gotData, _ := json.Marshal(got)
+	return bytes.Equal(wantData, gotData)
+}
@@ -0,0 +1,152 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides, after each attempt, whether the request should be sent again
+// and how long to wait before doing so.
+// attempt starts at 1; resp is nil when the attempt failed with a transport error,
+// including an attempt that hit the client timeout. The policy is not consulted once
+// the context given to the client is done.
+type RetryPolicy interface {
+	Retry(attempt int, req *http.Request, resp *http.Response, err error) (time.Duration, bool)
+}
+
+// DefaultRetryStatus is the set of status codes retried by NewExponentialBackoff.
+var DefaultRetryStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// ExponentialBackoff retries with a delay that doubles (by Multiplier) on each attempt,
+// randomized by Jitter and capped at MaxDelay.
+// A Retry-After header sent by the server takes precedence over the computed delay.
+// Only idempotent methods are retried unless RetryNonIdempotent is set, a request
+// carrying an Idempotency-Key header counts as idempotent.
+type ExponentialBackoff struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	// Jitter is the fraction of the delay, between 0 and 1, that is randomized.
+	Jitter float64
+	// RetryOn holds the status codes worth retrying.
+	RetryOn            []int
+	RetryNonIdempotent bool
+}
+
+// NewExponentialBackoff creates an ExponentialBackoff with sensible defaults:
+// 100ms initial delay, 5s max delay, factor 2, 20% jitter and DefaultRetryStatus.
+func NewExponentialBackoff(maxAttempts int) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		MaxAttempts:  maxAttempts,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     5 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+		RetryOn:      DefaultRetryStatus,
+	}
+}
+
+func (b *ExponentialBackoff) Retry(attempt int, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= b.MaxAttempts {
+		return 0, false
+	}
+	if !b.RetryNonIdempotent && !isIdempotent(req) {
+		return 0, false
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrCircuitOpen) {
+			return 0, false
+		}
+		return b.delay(attempt), true
+	}
+
+	if !b.retryStatus(resp.StatusCode) {
+		return 0, false
+	}
+	if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+		return wait, true
+	}
+	return b.delay(attempt), true
+}
+
+func (b *ExponentialBackoff) retryStatus(status int) bool {
+	for _, s := range b.RetryOn {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *ExponentialBackoff) delay(attempt int) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(b.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if b.MaxDelay > 0 && delay > float64(b.MaxDelay) {
+		delay = float64(b.MaxDelay)
+	}
+	if b.Jitter > 0 {
+		delay += delay * b.Jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(delay)
+}
+
+func isIdempotent(req *http.Request) bool {
+	if req.Header.Get("Idempotency-Key") != "" {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,187 @@
+package rest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreamResponse is a response whose body has not been read yet.
+// Body must be closed by the caller.
+type StreamResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       io.ReadCloser
+}
+
+// Stream sends req and hands back the response body unread, for downloads too big to
+// be buffered. The request timeout only covers the wait for the response headers,
+// use ctx to bound the time spent reading the body.
+// A non success status is returned as an HTTPError, as with ExecuteRequest.
+func (client *RestClient) Stream(ctx context.Context, req *Request) (*StreamResponse, error) {
+	request, resp, cancel, err := client.roundTrip(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		defer cancel()
+		_, err = client.doRequest(req, request, resp)
+		return nil, err
+	}
+
+	return &StreamResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       &cancelOnClose{ReadCloser: resp.Body, cancel: cancel},
+	}, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// NDJSON streams a newline delimited JSON response, yielding every value decoded into T.
+// Iteration stops at the end of the body, on the first error or when ctx is done.
+func NDJSON[T any](ctx context.Context, client *RestClient, req *Request) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		resp, err := client.Stream(ctx, req)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var value T
+			if err = decoder.Decode(&value); err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(zero, streamErr(ctx, err))
+				}
+				return
+			}
+			if !yield(value, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Event is a server-sent event.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	// Retry is the reconnection time requested by the server, zero when not sent.
+	Retry time.Duration
+}
+
+// Events streams a text/event-stream response, yielding every event sent by the server.
+// Iteration stops at the end of the body, on the first error or when ctx is done.
+func Events(ctx context.Context, client *RestClient, req *Request) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		if !hasHeader(req.options.headers, "Accept") {
+			req = req.With(RequestWithHeaders(mergeHeaders(req.options.headers, map[string]string{
+				"Accept": "text/event-stream",
+			})))
+		}
+
+		resp, err := client.Stream(ctx, req)
+		if err != nil {
+			yield(Event{}, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		var (
+			event   Event
+			data    strings.Builder
+			hasData bool
+		)
+		reader := bufio.NewReader(resp.Body)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+				if !errors.Is(err, io.EOF) {
+					yield(Event{}, streamErr(ctx, err))
+				}
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+
+			if line == "" {
+				if hasData {
+					event.Data = strings.TrimSuffix(data.String(), "\n")
+					if !yield(event, nil) {
+						return
+					}
+				}
+				event, hasData = Event{ID: event.ID}, false
+				data.Reset()
+				continue
+			}
+
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "":
+				// comment line
+			case "event":
+				event.Event = value
+			case "data":
+				data.WriteString(value)
+				data.WriteByte('\n')
+				hasData = true
+			case "id":
+				event.ID = value
+			case "retry":
+				if ms, convErr := strconv.Atoi(value); convErr == nil {
+					event.Retry = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+	}
+}
+
+func streamErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func mergeHeaders(headers map[string]string, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(headers)+len(extra))
+	for k, v := range headers {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("large export"))
+	}))
+	defer server.Close()
+
+	resp, err := NewHttpClient().Stream(context.Background(), NewRequest(server.URL, http.MethodGet))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "large export", string(data))
+}
+
+func TestNDJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"))
+	}))
+	defer server.Close()
+
+	var ids []int
+	for u, err := range NDJSON[user](context.Background(), NewHttpClient(), NewRequest(server.URL, http.MethodGet)) {
+		require.NoError(t, err)
+		ids = append(ids, u.ID)
+	}
+	require.Equal(t, []int{1, 2, 3}, ids)
+}
+
+func TestEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(": keep-alive\n\nid: 1\nevent: payment\ndata: {\"txid\":\"a\"}\n\n" +
+			"id: 2\ndata: line 1\ndata: line 2\nretry: 1500\n\n"))
+	}))
+	defer server.Close()
+
+	var events []Event
+	for event, err := range Events(context.Background(), NewHttpClient(), NewRequest(server.URL, http.MethodGet)) {
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+	require.Equal(t, []Event{
+		{ID: "1", Event: "payment", Data: `{"txid":"a"}`},
+		{ID: "2", Data: "line 1\nline 2", Retry: 1500 * time.Millisecond},
+	}, events)
+}
@@ -0,0 +1,210 @@
+package rest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// WithTLSConfig sets the TLS configuration of the client transport, replacing the one
+// of the transport given with WithTransport. The other TLS options are applied on top
+// of a copy of it, whatever their order.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return ClientOptionFunc(func(c *RestClient) {
+		c.tlsConfig = config.Clone()
+	})
+}
+
+// WithClientCertificate presents cert to servers requiring mutual TLS,
+// see LoadClientCertificate, ParseClientCertificate and ParsePKCS12 to obtain it.
+func WithClientCertificate(cert tls.Certificate) ClientOption {
+	return withTLSOption(func(config *tls.Config) {
+		config.Certificates = append(config.Certificates, cert)
+	})
+}
+
+// WithRootCAs replaces the system roots used to verify servers, see LoadRootCAs.
+func WithRootCAs(pool *x509.CertPool) ClientOption {
+	return withTLSOption(func(config *tls.Config) {
+		config.RootCAs = pool
+	})
+}
+
+// WithMinTLSVersion sets the minimum TLS version accepted, e.g. tls.VersionTLS12.
+func WithMinTLSVersion(version uint16) ClientOption {
+	return withTLSOption(func(config *tls.Config) {
+		config.MinVersion = version
+	})
+}
+
+// WithCertificatePins only accepts servers whose verified certificate chain contains a public key
+// matching one of the pins, given as base64 SHA-256 digests of the DER encoded
+// SubjectPublicKeyInfo, optionally prefixed with "sha256/".
+// Pinning is done on top of the regular certificate verification.
+func WithCertificatePins(pins ...string) ClientOption {
+	return ClientOptionFunc(func(c *RestClient) {
+		for _, pin := range pins {
+			c.certificatePins = append(c.certificatePins, strings.TrimPrefix(pin, "sha256/"))
+		}
+	})
+}
+
+func withTLSOption(option func(*tls.Config)) ClientOption {
+	return ClientOptionFunc(func(c *RestClient) {
+		c.tlsOptions = append(c.tlsOptions, option)
+	})
+}
+
+// applyTLS installs the TLS options on a clone of the transport. They start from the
+// WithTLSConfig configuration, or else from the one of the transport, so its own
+// settings are kept. TLS options need an *http.Transport: with any other transport
+// every request fails rather than being sent without them, see WithTransportWrapper.
+func (client *RestClient) applyTLS() {
+	if client.tlsConfig == nil && len(client.tlsOptions) == 0 && len(client.certificatePins) == 0 {
+		return
+	}
+
+	transport, ok := client.transport.(*http.Transport)
+	if !ok {
+		err := fmt.Errorf("[restClient] TLS options require an *http.Transport, the transport is a %T: "+
+			"wrap it with WithTransportWrapper instead", client.transport)
+		client.transport = failingTransport{err: err}
+		return
+	}
+
+	config := client.tlsConfig
+	switch {
+	case config != nil:
+		config = config.Clone()
+	case transport.TLSClientConfig != nil:
+		config = transport.TLSClientConfig.Clone()
+	default:
+		config = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	for _, option := range client.tlsOptions {
+		option(config)
+	}
+
+	if pins := client.certificatePins; len(pins) > 0 {
+		verify := config.VerifyConnection
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if verify != nil {
+				if err := verify(state); err != nil {
+					return err
+				}
+			}
+			return verifyPins(state, pins)
+		}
+	}
+
+	transport = transport.Clone()
+	transport.TLSClientConfig = config
+	client.transport = transport
+}
+
+// failingTransport fails every request with err.
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	return nil, t.err
+}
+
+// verifyPins looks for the pins in the verified chains only: the server can send any
+// certificate, a pinned one appended to another chain must not be accepted.
+func verifyPins(state tls.ConnectionState, pins []string) error {
+	for _, chain := range state.VerifiedChains {
+		for _, cert := range chain {
+			sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			fingerprint := base64.StdEncoding.EncodeToString(sum[:])
+			for _, pin := range pins {
+				if pin == fingerprint {
+					return nil
+				}
+			}
+		}
+	}
+	return fmt.Errorf("[restClient] no certificate of %s matches the configured pins", state.ServerName)
+}
+
+// CertificatePin returns the pin of cert as expected by WithCertificatePins.
+func CertificatePin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// LoadClientCertificate reads a PEM encoded certificate and private key pair from files.
+func LoadClientCertificate(certFile, keyFile string) (tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("[restClient] error loading client certificate: %v", err)
+	}
+	return cert, nil
+}
+
+// ParseClientCertificate parses a PEM encoded certificate and private key pair.
+// Both may be given in the same block of bytes.
+func ParseClientCertificate(certPEM, keyPEM []byte) (tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("[restClient] error parsing client certificate: %v", err)
+	}
+	return cert, nil
+}
+
+// ParsePKCS12 decodes a PKCS#12 (.p12, .pfx) bundle, like the ones issued by Efí,
+// into a client certificate.
+func ParsePKCS12(data []byte, password string) (tls.Certificate, error) {
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("[restClient] error decoding pkcs12: %v", err)
+	}
+
+	var certPEM, keyPEM bytes.Buffer
+	for _, block := range blocks {
+		if block.Type == "CERTIFICATE" {
+			_ = pem.Encode(&certPEM, block)
+		} else {
+			_ = pem.Encode(&keyPEM, block)
+		}
+	}
+	return ParseClientCertificate(certPEM.Bytes(), keyPEM.Bytes())
+}
+
+// LoadPKCS12 reads a PKCS#12 bundle from file, see ParsePKCS12.
+func LoadPKCS12(file, password string) (tls.Certificate, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("[restClient] error reading pkcs12 file: %v", err)
+	}
+	return ParsePKCS12(data, password)
+}
+
+// LoadRootCAs builds a certificate pool from PEM encoded CA files.
+func LoadRootCAs(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("[restClient] error reading CA file: %v", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, errors.New("[restClient] no certificate found in CA file " + file)
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Len(t, r.TLS.PeerCertificates, 1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	clientCert := server.TLS.Certificates[0]
+	req := NewRequest(server.URL, http.MethodGet)
+
+	client := NewHttpClient(
+		WithRootCAs(roots),
+		WithClientCertificate(clientCert),
+		WithMinTLSVersion(tls.VersionTLS12),
+		WithCertificatePins(CertificatePin(server.Certificate())),
+	)
+	require.NoError(t, client.ExecuteRequest(context.Background(), req))
+
+	pinned := NewHttpClient(
+		WithRootCAs(roots),
+		WithClientCertificate(clientCert),
+		WithCertificatePins("sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="),
+	)
+	require.ErrorContains(t, pinned.ExecuteRequest(context.Background(), req), "matches the configured pins")
+}
+
+func TestClientTLS_pinOutsideVerifiedChain(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "pinned"}, NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	pinned, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	server.StartTLS()
+	defer server.Close()
+	// the server sends the pinned certificate, which is not part of the chain it is verified with
+	server.TLS.Certificates[0].Certificate = append(server.TLS.Certificates[0].Certificate, der)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	client := NewHttpClient(WithRootCAs(roots), WithCertificatePins(CertificatePin(pinned)))
+	err = client.ExecuteRequest(context.Background(), NewRequest(server.URL, http.MethodGet))
+	require.ErrorContains(t, err, "matches the configured pins")
+}
+
+func TestClientTLS_transportConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	req := NewRequest(server.URL, http.MethodGet)
+
+	// the server client trusts the test certificate, which must survive the TLS options
+	client := NewHttpClient(WithTransport(server.Client().Transport), WithMinTLSVersion(tls.VersionTLS12))
+	require.NoError(t, client.ExecuteRequest(context.Background(), req))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	client = NewHttpClient(WithRootCAs(roots), WithTLSConfig(&tls.Config{ServerName: "example.com"}))
+	require.NoError(t, client.ExecuteRequest(context.Background(), req))
+
+	pin := CertificatePin(server.Certificate())
+	wrapped := roundTripperFunc(server.Client().Transport.RoundTrip)
+	client = NewHttpClient(WithTransport(wrapped), WithCertificatePins(pin))
+	require.ErrorContains(t, client.ExecuteRequest(context.Background(), req), "TLS options require an *http.Transport")
+
+	var wrapperCalls int
+	client = NewHttpClient(
+		WithRootCAs(roots),
+		WithCertificatePins(pin),
+		WithTransportWrapper(func(next http.RoundTripper) http.RoundTripper {
+			require.IsType(t, &http.Transport{}, next)
+			return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				wrapperCalls++
+				return next.RoundTrip(r)
+			})
+		}),
+	)
+	require.NoError(t, client.ExecuteRequest(context.Background(), req))
+	require.Equal(t, 1, wrapperCalls)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}